@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+)
+
+// Every chunk file has a sidecar <file>.crc holding one CRC-32C per
+// checksumBlock bytes of data. It is written alongside the data and renamed
+// with it, and reads verify each block they touch, so a range read only
+// checks the blocks that cover the range.
+const checksumBlock = 64 * 1024
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	// errCorrupt is returned when a block no longer matches its checksum.
+	errCorrupt = errors.New("chunk data does not match its checksum")
+	// errRange is returned for a range that starts past the end of the chunk.
+	errRange = errors.New("range not satisfiable")
+)
+
+func crcPath(path string) string {
+	return path + ".crc"
+}
+
+// blockSummer checksums everything written through it in checksumBlock
+// pieces.
+type blockSummer struct {
+	sums []uint32
+	cur  uint32
+	n    int // bytes in the current block
+}
+
+func (b *blockSummer) Write(p []byte) (int, error) {
+	total := len(p)
+	for len(p) > 0 {
+		m := min(len(p), checksumBlock-b.n)
+		b.cur = crc32.Update(b.cur, crcTable, p[:m])
+		b.n += m
+		p = p[m:]
+		if b.n == checksumBlock {
+			b.sums = append(b.sums, b.cur)
+			b.cur, b.n = 0, 0
+		}
+	}
+	return total, nil
+}
+
+// finish returns the checksums, the last one covering a short block.
+func (b *blockSummer) finish() []uint32 {
+	if b.n > 0 {
+		b.sums = append(b.sums, b.cur)
+		b.cur, b.n = 0, 0
+	}
+	return b.sums
+}
+
+func writeChecksums(path string, sums []uint32) error {
+	buf := make([]byte, 4*len(sums))
+	for i, s := range sums {
+		binary.BigEndian.PutUint32(buf[4*i:], s)
+	}
+	return os.WriteFile(path, buf, 0644)
+}
+
+func readChecksums(path string) ([]uint32, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("%s: truncated checksum file", path)
+	}
+	sums := make([]uint32, len(buf)/4)
+	for i := range sums {
+		sums[i] = binary.BigEndian.Uint32(buf[4*i:])
+	}
+	return sums, nil
+}
+
+// rangeReader returns bytes [start, end) of a committed chunk, reading and
+// verifying the whole checksum blocks that hold them.
+type rangeReader struct {
+	chunkID string
+	f       *os.File
+	sums    []uint32 // nil for chunks stored before checksums existed
+	size    int64    // bytes in the chunk
+	start   int64
+	end     int64
+	next    int64  // offset of the next block to load
+	block   []byte // scratch for one block
+	buf     []byte // verified bytes not yet returned
+}
+
+// openRange opens n bytes of chunkID at off for reading; n 0 means through
+// the end of the chunk. The first block is read and verified before
+// openRange returns, so a corrupt start fails before any data is sent.
+func (s *chunkStore) openRange(chunkID string, off, n int64) (*rangeReader, error) {
+	path := s.chunkPath(chunkID)
+	if path == "" {
+		return nil, os.ErrNotExist
+	}
+	f, sums, err := s.openWithSums(path)
+	if err != nil {
+		return nil, err
+	}
+	if sums == nil {
+		log.Printf("chunk %s has no checksums, serving it unverified", chunkID)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		s.checkErr(s.diskOf(path), err)
+		return nil, err
+	}
+	size := info.Size()
+	if off < 0 || n < 0 || off > size {
+		f.Close()
+		return nil, errRange
+	}
+	end := size
+	if n > 0 {
+		end = min(off+n, size)
+	}
+
+	r := &rangeReader{
+		chunkID: chunkID,
+		f:       f,
+		sums:    sums,
+		size:    size,
+		start:   off,
+		end:     end,
+		next:    off / checksumBlock * checksumBlock,
+	}
+	if r.start < r.end {
+		if err := r.fill(); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// openWithSums opens the chunk file at path and loads its checksums, nil if
+// it has none. Both are taken while no install can swap them, so they
+// belong to the same write even if the chunk is rewritten right after.
+func (s *chunkStore) openWithSums(path string) (*os.File, []uint32, error) {
+	s.swap.RLock()
+	defer s.swap.RUnlock()
+	f, err := os.Open(path)
+	if err != nil {
+		s.checkErr(s.diskOf(path), err)
+		return nil, nil, err
+	}
+	sums, err := readChecksums(crcPath(path))
+	if err != nil && !os.IsNotExist(err) {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, sums, nil
+}
+
+// Len returns how many bytes the range holds.
+func (r *rangeReader) Len() int64 {
+	return r.end - r.start
+}
+
+// fill loads and verifies the block at r.next.
+func (r *rangeReader) fill() error {
+	if r.block == nil {
+		r.block = make([]byte, checksumBlock)
+	}
+	n := min(checksumBlock, r.size-r.next)
+	data := r.block[:n]
+	if _, err := r.f.ReadAt(data, r.next); err != nil {
+		return err
+	}
+	if r.sums != nil {
+		i := r.next / checksumBlock
+		if i >= int64(len(r.sums)) || crc32.Checksum(data, crcTable) != r.sums[i] {
+			return fmt.Errorf("%w: %s block %d", errCorrupt, r.chunkID, i)
+		}
+	}
+	lo := max(r.start-r.next, 0)
+	hi := min(r.end-r.next, n)
+	r.buf = data[lo:hi]
+	r.next += n
+	return nil
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		if r.next >= r.end {
+			return 0, io.EOF
+		}
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *rangeReader) Close() error {
+	return r.f.Close()
+}
+
+// checkCorrupt drops chunkID and reports it lost if err says its data went
+// bad, so the master re-replicates it from a good copy.
+func checkCorrupt(chunkID string, err error) {
+	if !errors.Is(err, errCorrupt) {
+		return
+	}
+	log.Printf("chunk %s is corrupt, dropping it: %v", chunkID, err)
+	if err := deleteChunk(chunkID); err != nil {
+		log.Printf("dropping corrupt chunk %s: %v", chunkID, err)
+	}
+	go reportLostChunks([]string{chunkID})
+}
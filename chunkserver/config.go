@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Config holds chunkserver settings. Flags win over environment variables,
+// which win over the built-in defaults.
+type Config struct {
+	Port      string
+	Advertise string // host:port other nodes and the master use to reach us
+	MasterURL string
+	DataDirs  []string // one per disk
+	Rack      string   // failure domain label, e.g. rack or zone
+
+	GRPCPort      string // "0" disables the gRPC API
+	GRPCAdvertise string // host:port the gRPC API is reached on
+}
+
+func envOr(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+func loadConfig(args []string) (Config, error) {
+	var c Config
+	fs := flag.NewFlagSet("chunkserver", flag.ContinueOnError)
+	fs.StringVar(&c.Port, "port", envOr("PORT", "9001"), "chunkserver port")
+	fs.StringVar(&c.Advertise, "advertise", os.Getenv("GFS_CHUNK_ADVERTISE"), "advertised host:port (default localhost:<port>)")
+	fs.StringVar(&c.MasterURL, "master", envOr("GFS_MASTER_URL", "http://master:8080"), "master address")
+	fs.StringVar(&c.Rack, "rack", os.Getenv("GFS_CHUNK_RACK"), "rack or zone label used to spread replicas")
+	fs.StringVar(&c.GRPCPort, "grpc-port", os.Getenv("GFS_CHUNK_GRPC_PORT"), "gRPC port (default <port>+1000, 0 to disable)")
+	dirs := fs.String("data-dir", envOr("GFS_CHUNK_DATA_DIR", "data"), "comma-separated directories for chunk files, one per disk")
+	if err := fs.Parse(args); err != nil {
+		return c, err
+	}
+
+	for _, d := range strings.Split(*dirs, ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			c.DataDirs = append(c.DataDirs, d)
+		}
+	}
+	if len(c.DataDirs) == 0 {
+		return c, errors.New("at least one data dir required")
+	}
+
+	if c.Advertise == "" {
+		c.Advertise = "localhost:" + c.Port
+	}
+	if c.GRPCPort == "" {
+		port, err := strconv.Atoi(c.Port)
+		if err != nil {
+			return c, fmt.Errorf("invalid port %q", c.Port)
+		}
+		c.GRPCPort = strconv.Itoa(port + 1000)
+	}
+	if c.GRPCPort != "0" {
+		host, _, err := net.SplitHostPort(c.Advertise)
+		if err != nil {
+			return c, fmt.Errorf("invalid advertise address %q: %w", c.Advertise, err)
+		}
+		c.GRPCAdvertise = net.JoinHostPort(host, c.GRPCPort)
+	}
+	if !strings.HasPrefix(c.MasterURL, "http://") && !strings.HasPrefix(c.MasterURL, "https://") {
+		c.MasterURL = "http://" + c.MasterURL
+	}
+	c.MasterURL = strings.TrimRight(c.MasterURL, "/")
+	return c, nil
+}
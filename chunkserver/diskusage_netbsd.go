@@ -0,0 +1,12 @@
+package main
+
+import "golang.org/x/sys/unix"
+
+// diskUsage returns total and available bytes of the filesystem holding dir.
+func diskUsage(dir string) (total, free uint64, err error) {
+	var st unix.Statvfs_t
+	if err := unix.Statvfs(dir, &st); err != nil {
+		return 0, 0, err
+	}
+	return st.Blocks * st.Frsize, st.Bavail * st.Frsize, nil
+}
@@ -0,0 +1,12 @@
+package main
+
+import "syscall"
+
+// diskUsage returns total and available bytes of the filesystem holding dir.
+func diskUsage(dir string) (total, free uint64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, 0, err
+	}
+	return st.F_blocks * uint64(st.F_bsize), uint64(st.F_bavail) * uint64(st.F_bsize), nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd && !dragonfly && !openbsd && !netbsd
+
+package main
+
+import "errors"
+
+func diskUsage(dir string) (total, free uint64, err error) {
+	return 0, 0, errors.New("disk usage not supported on this platform")
+}
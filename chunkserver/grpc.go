@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+
+	"gfs/internal/rpc"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcChunkServer serves the rpc.ChunkServer API with the same storage and
+// replication logic as the HTTP handlers.
+type grpcChunkServer struct {
+	rpc.UnimplementedChunkServerServer
+}
+
+func (grpcChunkServer) WriteChunk(stream rpc.ChunkServer_WriteChunkServer) error {
+	inflightWrites.Add(1)
+	defer inflightWrites.Add(-1)
+
+	cr, err := rpc.NewChunkReader(stream.Recv)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	chunkID := cr.Meta().ChunkId
+	if chunkID == "" {
+		return status.Error(codes.InvalidArgument, "chunk_id required")
+	}
+	if _, err := store.writeChunk(chunkID, cr, cr.Meta().Version); err != nil {
+		return status.Error(codes.Internal, "failed to write chunk")
+	}
+	log.Printf("stored chunk %s", chunkID)
+	return stream.SendAndClose(&rpc.WriteResult{})
+}
+
+func (grpcChunkServer) ReadChunk(req *rpc.ReadChunkRequest, stream rpc.ChunkServer_ReadChunkServer) error {
+	inflightReads.Add(1)
+	defer inflightReads.Add(-1)
+
+	if req.ChunkId == "" {
+		return status.Error(codes.InvalidArgument, "chunk_id required")
+	}
+	rr, err := store.openRange(req.ChunkId, req.Offset, req.Length)
+	switch {
+	case errors.Is(err, errRange):
+		return status.Error(codes.OutOfRange, "offset past the end of the chunk")
+	case errors.Is(err, errCorrupt):
+		checkCorrupt(req.ChunkId, err)
+		return status.Error(codes.DataLoss, "chunk corrupt")
+	case err != nil:
+		return status.Error(codes.NotFound, "chunk not found")
+	}
+	defer rr.Close()
+
+	if err := rpc.SendChunk(stream.Send, &rpc.ChunkData{ChunkId: req.ChunkId}, rr); err != nil {
+		log.Printf("read of chunk %s aborted: %v", req.ChunkId, err)
+		checkCorrupt(req.ChunkId, err)
+		return err
+	}
+	return nil
+}
+
+func (grpcChunkServer) WritePrimary(stream rpc.ChunkServer_WritePrimaryServer) error {
+	inflightWrites.Add(1)
+	defer inflightWrites.Add(-1)
+
+	cr, err := rpc.NewChunkReader(stream.Recv)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if cr.Meta().ChunkId == "" {
+		return status.Error(codes.InvalidArgument, "chunk_id required")
+	}
+	seq, err := writePrimary(cr.Meta().ChunkId, cr)
+	if err != nil {
+		return grpcError(err)
+	}
+	return stream.SendAndClose(&rpc.WriteResult{Seq: seq})
+}
+
+func (grpcChunkServer) ApplyWrite(stream rpc.ChunkServer_ApplyWriteServer) error {
+	inflightWrites.Add(1)
+	defer inflightWrites.Add(-1)
+
+	cr, err := rpc.NewChunkReader(stream.Recv)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	meta := cr.Meta()
+	if meta.ChunkId == "" {
+		return status.Error(codes.InvalidArgument, "chunk_id required")
+	}
+	if err := applyWrite(meta.ChunkId, meta.Seq, meta.Version, cr); err != nil {
+		return grpcError(err)
+	}
+	return stream.SendAndClose(&rpc.WriteResult{Seq: meta.Seq})
+}
+
+func (grpcChunkServer) Commit(ctx context.Context, req *rpc.CommitRequest) (*rpc.WriteResult, error) {
+	if err := commitWrite(req.ChunkId, req.Seq); err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpc.WriteResult{Seq: req.Seq}, nil
+}
+
+func (grpcChunkServer) CopyChunk(ctx context.Context, req *rpc.CopyChunkRequest) (*rpc.WriteResult, error) {
+	inflightReads.Add(1)
+	defer inflightReads.Add(-1)
+
+	if req.ChunkId == "" || req.Target == "" {
+		return nil, status.Error(codes.InvalidArgument, "chunk_id and target required")
+	}
+	if err := copyChunk(req.ChunkId, req.Target); err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpc.WriteResult{}, nil
+}
+
+func (grpcChunkServer) DeleteChunk(ctx context.Context, req *rpc.DeleteChunkRequest) (*rpc.WriteResult, error) {
+	inflightWrites.Add(1)
+	defer inflightWrites.Add(-1)
+
+	if req.ChunkId == "" {
+		return nil, status.Error(codes.InvalidArgument, "chunk_id required")
+	}
+	if err := deleteChunk(req.ChunkId); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &rpc.WriteResult{}, nil
+}
+
+func (grpcChunkServer) ChunkReport(ctx context.Context, req *rpc.ChunkReportRequest) (*rpc.ChunkReportResponse, error) {
+	return &rpc.ChunkReportResponse{Uuid: nodeID, Chunks: store.chunkIDs(), Versions: store.chunkVersions()}, nil
+}
+
+// grpcError converts an *apiError's HTTP status to the matching gRPC code.
+func grpcError(err error) error {
+	ae, ok := err.(*apiError)
+	if !ok {
+		return status.Error(codes.Internal, err.Error())
+	}
+	code := codes.Unknown
+	switch ae.code {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusInsufficientStorage:
+		code = codes.ResourceExhausted
+	case http.StatusBadGateway:
+		code = codes.Unavailable
+	case http.StatusInternalServerError:
+		code = codes.Internal
+	}
+	return status.Error(code, ae.msg)
+}
+
+// startGRPCServer serves the gRPC API on port in the background. It returns
+// nil when port is "0".
+func startGRPCServer(port string) (*grpc.Server, error) {
+	if port == "0" {
+		return nil, nil
+	}
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen for gRPC: %w", err)
+	}
+	srv := grpc.NewServer()
+	rpc.RegisterChunkServerServer(srv, grpcChunkServer{})
+	go func() {
+		log.Printf("chunk-server: gRPC starting on %s", lis.Addr())
+		if err := srv.Serve(lis); err != nil {
+			log.Fatalf("chunk-server: gRPC Serve error: %v", err)
+		}
+	}()
+	return srv, nil
+}
@@ -3,12 +3,13 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 )
 
@@ -20,6 +21,7 @@ type copyChunkReq struct {
 type receiveChunkReq struct {
 	ChunkID string `json:"chunk_id"`
 	Data    []byte `json:"data"`
+	Version uint64 `json:"version,omitempty"`
 }
 
 type genericResp struct {
@@ -46,6 +48,28 @@ type applyWriteReq struct {
 	Version uint64 `json:"version,omitempty"`
 }
 
+// apiError is a request failure shared by the HTTP and gRPC front ends. code
+// is the HTTP status; grpc.go maps it to a gRPC code.
+type apiError struct {
+	code int
+	msg  string
+}
+
+func (e *apiError) Error() string { return e.msg }
+
+func errorf(code int, format string, args ...any) *apiError {
+	return &apiError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+// writeError sends err to an HTTP client, as a 500 unless it is an *apiError.
+func writeError(w http.ResponseWriter, err error) {
+	if ae, ok := err.(*apiError); ok {
+		http.Error(w, ae.msg, ae.code)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
 func helloHandler(serverAddr string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "chunk-server %s online\n", serverAddr)
@@ -60,10 +84,15 @@ func writeChunkHandler(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	var req WriteChunkRequest
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
-		return
+	var body io.Reader = r.Body
+	if isStream(r) {
+		req.ChunkID = r.Header.Get(headerChunkID)
+	} else {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		body = bytes.NewReader(req.Data)
 	}
 
 	if req.ChunkID == "" {
@@ -71,9 +100,9 @@ func writeChunkHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// saving file to disk
-	filename := "data/" + req.ChunkID + ".bin"
-	if err := os.WriteFile(filename, req.Data, 0644); err != nil {
+	// saving file to disk; direct writes bypass the master, so the version
+	// is unknown
+	if _, err := store.writeChunk(req.ChunkID, body, 0); err != nil {
 		http.Error(w, "failed to write chunk", http.StatusInternalServerError)
 		return
 	}
@@ -82,30 +111,79 @@ func writeChunkHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// /read_chunk?chunk_id=<id>[&offset=<n>&length=<n>] : the chunk's bytes, or
+// a range of them given by offset/length or a single-range Range header. Only
+// the checksum blocks covering the range are read and verified.
 func readChunkHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	chunkID := r.URL.Query().Get("chunk_id")
+	q := r.URL.Query()
+	chunkID := q.Get("chunk_id")
 	if chunkID == "" {
 		http.Error(w, "chunk_id required", http.StatusBadRequest)
 		return
 	}
+	off, err1 := queryInt(q.Get("offset"))
+	n, err2 := queryInt(q.Get("length"))
+	if err1 != nil || err2 != nil || off < 0 || n < 0 {
+		http.Error(w, "offset and length must be non-negative integers", http.StatusBadRequest)
+		return
+	}
 
-	filename := "data/" + chunkID + ".bin"
-	data, err := os.ReadFile(filename)
-	if err != nil {
+	size, ok := store.chunkSize(chunkID)
+	if !ok {
 		http.Error(w, "chunk not found", http.StatusNotFound)
 		return
 	}
+	partial := false
+	if h := r.Header.Get("Range"); h != "" {
+		var err error
+		if off, n, err = parseRange(h, size); err != nil {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			http.Error(w, err.Error(), http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		partial = true
+	}
 
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(data)
+	rr, err := store.openRange(chunkID, off, n)
+	switch {
+	case errors.Is(err, errRange):
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+		http.Error(w, "offset past the end of the chunk", http.StatusRequestedRangeNotSatisfiable)
+		return
+	case errors.Is(err, errCorrupt):
+		checkCorrupt(chunkID, err)
+		http.Error(w, "chunk corrupt", http.StatusInternalServerError)
+		return
+	case err != nil:
+		http.Error(w, "chunk not found", http.StatusNotFound)
+		return
+	}
+	defer rr.Close()
+
+	w.Header().Set("Content-Type", octetStream)
+	w.Header().Set("Content-Length", strconv.FormatInt(rr.Len(), 10))
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set(headerChunkID, chunkID)
+	w.Header().Set(headerChunkSize, strconv.FormatInt(size, 10))
+	if partial {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", off, off+rr.Len()-1, size))
+		w.WriteHeader(http.StatusPartialContent)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	if _, err := io.Copy(w, rr); err != nil {
+		// the short body tells the client the read failed
+		log.Printf("read of chunk %s aborted: %v", chunkID, err)
+		checkCorrupt(chunkID, err)
+	}
 }
 
-// copyChunkHandler: source chunkserver reads local chunk and posts to target's /receive_chunk.
+// copyChunkHandler: source chunkserver streams a local chunk to target's /receive_chunk.
 func copyChunkHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -124,41 +202,48 @@ func copyChunkHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// read local chunk file
-	filename := "data/" + req.ChunkID + ".bin"
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(genericResp{Status: "error", Message: "local chunk not found"})
+	if err := copyChunk(req.ChunkID, req.Target); err != nil {
+		code := http.StatusBadGateway
+		if ae, ok := err.(*apiError); ok {
+			code = ae.code
+		}
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(genericResp{Status: "error", Message: err.Error()})
 		return
 	}
 
-	// build receive payload
-	recv := receiveChunkReq{
-		ChunkID: req.ChunkID,
-		Data:    data,
+	// OK
+	json.NewEncoder(w).Encode(genericResp{Status: "ok"})
+}
+
+// copyChunk streams the local copy of chunkID to target's /receive_chunk.
+func copyChunk(chunkID, target string) error {
+	// read through the checksums so a corrupt copy is never spread
+	rr, err := store.openRange(chunkID, 0, 0)
+	if os.IsNotExist(err) {
+		return errorf(http.StatusNotFound, "local chunk not found")
 	}
-	b, _ := json.Marshal(recv)
-	url := fmt.Sprintf("http://%s/receive_chunk", req.Target)
+	if err != nil {
+		checkCorrupt(chunkID, err)
+		return errorf(http.StatusInternalServerError, "%v", err)
+	}
+	defer rr.Close()
 
+	url := fmt.Sprintf("http://%s/receive_chunk", target)
 	client := &http.Client{Timeout: 20 * time.Second}
-	resp, err := client.Post(url, "application/json", bytes.NewReader(b))
+	hdr := map[string]string{headerVersion: strconv.FormatUint(store.version(chunkID), 10)}
+	resp, err := postChunk(client, url, chunkID, rr, rr.Len(), hdr)
 	if err != nil {
-		w.WriteHeader(http.StatusBadGateway)
-		json.NewEncoder(w).Encode(genericResp{Status: "error", Message: err.Error()})
-		return
+		checkCorrupt(chunkID, err)
+		return errorf(http.StatusBadGateway, "%v", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode != http.StatusOK {
-		w.WriteHeader(http.StatusBadGateway)
-		json.NewEncoder(w).Encode(genericResp{Status: "error", Message: string(respBody)})
-		return
+		return errorf(http.StatusBadGateway, "%s", respBody)
 	}
-
-	// OK
-	json.NewEncoder(w).Encode(genericResp{Status: "ok"})
+	return nil
 }
 
 // receiveChunkHandler: accept chunk bytes and write locally.
@@ -170,9 +255,20 @@ func receiveChunkHandler(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	var req receiveChunkReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
-		return
+	var body io.Reader = r.Body
+	if isStream(r) {
+		var err error
+		req.ChunkID = r.Header.Get(headerChunkID)
+		if req.Version, err = headerUint(r, headerVersion); err != nil {
+			http.Error(w, "invalid version header", http.StatusBadRequest)
+			return
+		}
+	} else {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		body = bytes.NewReader(req.Data)
 	}
 
 	if req.ChunkID == "" {
@@ -180,8 +276,7 @@ func receiveChunkHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	filename := "data/" + req.ChunkID + ".bin"
-	if err := os.WriteFile(filename, req.Data, 0644); err != nil {
+	if _, err := store.writeChunk(req.ChunkID, body, req.Version); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(genericResp{Status: "error", Message: "failed to write chunk"})
 		return
@@ -196,42 +291,74 @@ func writePrimaryHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	defer r.Body.Close()
+
 	var req writePrimaryReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
-		return
+	var body io.Reader = r.Body
+	if isStream(r) {
+		req.ChunkID = r.Header.Get(headerChunkID)
+		req.ReqID = r.Header.Get(headerReqID)
+	} else {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		body = bytes.NewReader(req.Data)
 	}
 	if req.ChunkID == "" {
 		http.Error(w, "chunk_id required", http.StatusBadRequest)
 		return
 	}
-	log.Printf("WRITE_PRIMARY on %s for chunk=%s", serverAddr, req.ChunkID)
-	log.Printf("seq(before commit)=%d", lastApplied[req.ChunkID]+1)
+	seq, err := writePrimary(req.ChunkID, body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// respond to client
+	json.NewEncoder(w).Encode(genericResp{Status: "ok", Seq: seq})
+}
+
+// writePrimary runs a write as the chunk's primary: stage body locally,
+// forward it to every follower, then commit everywhere. It returns the
+// write's sequence number.
+func writePrimary(chunkID string, body io.Reader) (uint64, error) {
+	log.Printf("WRITE_PRIMARY on %s for chunk=%s", serverAddr, chunkID)
+	log.Printf("seq(before commit)=%d", lastApplied[chunkID]+1)
 
 	// 1) increment local seq
 	seqMu.Lock()
-	lastApplied[req.ChunkID]++
-	seq := lastApplied[req.ChunkID]
+	lastApplied[chunkID]++
+	seq := lastApplied[chunkID]
 	seqMu.Unlock()
 
-	// 2) write to local temp file: data/<chunkID>.seq.tmp
-	tmpFile := fmt.Sprintf("data/%s.%d.tmp", req.ChunkID, seq)
-	if err := os.WriteFile(tmpFile, req.Data, 0644); err != nil {
-		http.Error(w, "failed to write temp", http.StatusInternalServerError)
-		return
-	}
-
-	// 3) fetch replica list from master (so primary knows followers)
+	// 2) fetch replica list and version from master (so primary knows
+	// followers); the write makes the next version
 	var locResp struct {
 		Replicas []string `json:"replicas"`
 		Primary  string   `json:"primary"`
+		Version  uint64   `json:"version"`
+	}
+	if err := SendPostJSONAndDecode(masterURL+"/get_primary", map[string]string{"chunk_id": chunkID}, &locResp); err != nil {
+		return 0, errorf(http.StatusBadGateway, "replica lookup failed: %v", err)
 	}
-	_ = SendPostJSONAndDecode(masterURL+"/get_primary", map[string]string{"chunk_id": req.ChunkID}, &locResp)
 	// locResp.Replicas contains all replicas; primary is this server
+	version := locResp.Version + 1
+
+	// 3) stream the data to a local temp file: <disk>/<chunkID>.<seq>.tmp
+	d, err := store.diskFor(chunkID)
+	if err != nil {
+		return 0, errorf(http.StatusInsufficientStorage, "%v", err)
+	}
+	tmpFile := d.tmpPath(chunkID, seq)
+	size, err := store.writeFile(d, tmpFile, body, version)
+	if err != nil {
+		return 0, errorf(http.StatusInternalServerError, "failed to write temp")
+	}
 
 	// build follower list (exclude self)
 	var followers []string
-	addr := serverAddr // assume you have serverAddr string for this chunkserver e.g. "localhost:9001"
+	addr := serverAddr // advertised address, matches the master's replica IDs
 	for _, raddr := range locResp.Replicas {
 		if raddr == addr {
 			continue
@@ -239,26 +366,24 @@ func writePrimaryHandler(w http.ResponseWriter, r *http.Request) {
 		followers = append(followers, raddr)
 	}
 
-	// 4) send /apply_write to followers in parallel
-	apply := applyWriteReq{
-		ChunkID: req.ChunkID,
-		Seq:     seq,
-		Data:    req.Data,
+	// 4) stream the staged file to followers' /apply_write in parallel
+	hdr := map[string]string{
+		headerSeq:     strconv.FormatUint(seq, 10),
+		headerVersion: strconv.FormatUint(version, 10),
 	}
-	b, _ := json.Marshal(apply)
 	client := &http.Client{Timeout: 10 * time.Second}
 	ackCh := make(chan error, len(followers))
 
 	for _, f := range followers {
 		go func(faddr string) {
-			resp, err := client.Post("http://"+faddr+"/apply_write", "application/json", bytes.NewReader(b))
+			resp, err := postChunkFile(client, "http://"+faddr+"/apply_write", chunkID, tmpFile, hdr)
 			if err != nil {
 				ackCh <- err
 				return
 			}
 			defer resp.Body.Close()
 			if resp.StatusCode != http.StatusOK {
-				body, _ := ioutil.ReadAll(resp.Body)
+				body, _ := io.ReadAll(resp.Body)
 				ackCh <- fmt.Errorf("bad status %d: %s", resp.StatusCode, string(body))
 				return
 			}
@@ -270,27 +395,54 @@ func writePrimaryHandler(w http.ResponseWriter, r *http.Request) {
 	for i := 0; i < len(followers); i++ {
 		if err := <-ackCh; err != nil {
 			// rollback local temp file
-			_ = os.Remove(tmpFile)
-			http.Error(w, fmt.Sprintf("follower ack failed: %v", err), http.StatusBadGateway)
-			return
+			_ = removeFile(tmpFile)
+			return 0, errorf(http.StatusBadGateway, "follower ack failed: %v", err)
 		}
 	}
 
 	// 5) commit locally: rename tmp -> stable
-	finalFile := fmt.Sprintf("data/%s.bin", req.ChunkID)
-	if err := os.Rename(tmpFile, finalFile); err != nil {
-		http.Error(w, "failed to commit", http.StatusInternalServerError)
-		return
+	if err := store.install(d, tmpFile, chunkID); err != nil {
+		return 0, errorf(http.StatusInternalServerError, "failed to commit")
 	}
+	store.committed(chunkID, d, size, version)
 
 	// update committed seq
 	seqMu.Lock()
-	lastCommitted[req.ChunkID] = seq
+	lastCommitted[chunkID] = seq
 	seqMu.Unlock()
 
-	// 6) optionally tell followers to commit (we assume apply_write performed durable write already)
-	// respond to client
-	json.NewEncoder(w).Encode(genericResp{Status: "ok", Seq: seq})
+	// 6) tell followers to commit so their copy becomes readable
+	commit, _ := json.Marshal(commitReq{ChunkID: chunkID, Seq: seq})
+	commitCh := make(chan error, len(followers))
+	for _, f := range followers {
+		go func(faddr string) {
+			resp, err := client.Post("http://"+faddr+"/commit", "application/json", bytes.NewReader(commit))
+			if err != nil {
+				commitCh <- err
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				body, _ := io.ReadAll(resp.Body)
+				commitCh <- fmt.Errorf("%s: bad status %d: %s", faddr, resp.StatusCode, string(body))
+				return
+			}
+			commitCh <- nil
+		}(f)
+	}
+	for i := 0; i < len(followers); i++ {
+		if err := <-commitCh; err != nil {
+			return 0, errorf(http.StatusBadGateway, "follower commit failed: %v", err)
+		}
+	}
+
+	// 7) every replica has the data: tell the master how long the chunk is
+	// now, so readers see the new file length
+	report := map[string]any{"chunk_id": chunkID, "addr": serverAddr, "length": size, "version": version}
+	if err := SendPostJSONAndDecode(masterURL+"/report_write", report, &genericResp{}); err != nil {
+		return 0, errorf(http.StatusBadGateway, "report write to master failed: %v", err)
+	}
+	return seq, nil
 }
 
 // /apply_write : primary -> follower (write temp and ack)
@@ -299,27 +451,60 @@ func applyWriteHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	defer r.Body.Close()
+
 	var req applyWriteReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+	var body io.Reader = r.Body
+	if isStream(r) {
+		var err error
+		req.ChunkID = r.Header.Get(headerChunkID)
+		if req.Seq, err = headerUint(r, headerSeq); err == nil {
+			req.Version, err = headerUint(r, headerVersion)
+		}
+		if err != nil {
+			http.Error(w, "invalid seq or version header", http.StatusBadRequest)
+			return
+		}
+	} else {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		body = bytes.NewReader(req.Data)
+	}
+	if req.ChunkID == "" {
+		http.Error(w, "chunk_id required", http.StatusBadRequest)
 		return
 	}
 
-	tmpFile := fmt.Sprintf("data/%s.%d.tmp", req.ChunkID, req.Seq)
-	if err := os.WriteFile(tmpFile, req.Data, 0644); err != nil {
-		http.Error(w, "failed to write temp", http.StatusInternalServerError)
+	if err := applyWrite(req.ChunkID, req.Seq, req.Version, body); err != nil {
+		writeError(w, err)
 		return
 	}
 
+	// ack
+	json.NewEncoder(w).Encode(genericResp{Status: "ok", Seq: req.Seq})
+}
+
+// applyWrite stages a write forwarded by the primary until it is committed.
+// version is what the chunk becomes when it is.
+func applyWrite(chunkID string, seq, version uint64, body io.Reader) error {
+	d, err := store.diskFor(chunkID)
+	if err != nil {
+		return errorf(http.StatusInsufficientStorage, "%v", err)
+	}
+	tmpFile := d.tmpPath(chunkID, seq)
+	if _, err := store.writeFile(d, tmpFile, body, version); err != nil {
+		return errorf(http.StatusInternalServerError, "failed to write temp")
+	}
+
 	// mark applied seq (durable enough for our toy)
 	seqMu.Lock()
-	if lastApplied[req.ChunkID] < req.Seq {
-		lastApplied[req.ChunkID] = req.Seq
+	if lastApplied[chunkID] < seq {
+		lastApplied[chunkID] = seq
 	}
 	seqMu.Unlock()
-
-	// ack
-	json.NewEncoder(w).Encode(genericResp{Status: "ok", Seq: req.Seq})
+	return nil
 }
 
 // /commit : primary -> follower commit (rename tmp -> final)
@@ -338,19 +523,98 @@ func commitHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid json", http.StatusBadRequest)
 		return
 	}
-	tmpFile := fmt.Sprintf("data/%s.%d.tmp", req.ChunkID, req.Seq)
-	finalFile := fmt.Sprintf("data/%s.bin", req.ChunkID)
-	if err := os.Rename(tmpFile, finalFile); err != nil {
-		http.Error(w, "commit failed", http.StatusInternalServerError)
+	if err := commitWrite(req.ChunkID, req.Seq); err != nil {
+		writeError(w, err)
 		return
 	}
+
+	json.NewEncoder(w).Encode(genericResp{Status: "ok", Seq: req.Seq})
+}
+
+// commitWrite makes the staged write seq of chunkID the readable copy.
+func commitWrite(chunkID string, seq uint64) error {
+	d, err := store.diskFor(chunkID)
+	if err != nil {
+		return errorf(http.StatusInsufficientStorage, "%v", err)
+	}
+	tmpFile := d.tmpPath(chunkID, seq)
+	info, err := os.Stat(tmpFile)
+	if err != nil {
+		return errorf(http.StatusInternalServerError, "commit failed")
+	}
+	version := readVersion(verPath(tmpFile))
+	if err := store.install(d, tmpFile, chunkID); err != nil {
+		return errorf(http.StatusInternalServerError, "commit failed")
+	}
+	store.committed(chunkID, d, info.Size(), version)
 	seqMu.Lock()
-	if lastCommitted[req.ChunkID] < req.Seq {
-		lastCommitted[req.ChunkID] = req.Seq
+	if lastCommitted[chunkID] < seq {
+		lastCommitted[chunkID] = seq
 	}
 	seqMu.Unlock()
+	return nil
+}
 
-	json.NewEncoder(w).Encode(genericResp{Status: "ok", Seq: req.Seq})
+type deleteChunkReq struct {
+	ChunkID string `json:"chunk_id"`
+}
+
+// /delete_chunk : master -> chunkserver, drop a replica that is no longer wanted
+func deleteChunkHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req deleteChunkReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.ChunkID == "" {
+		http.Error(w, "chunk_id required", http.StatusBadRequest)
+		return
+	}
+
+	if err := deleteChunk(req.ChunkID); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(genericResp{Status: "error", Message: err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(genericResp{Status: "ok"})
+}
+
+func deleteChunk(chunkID string) error {
+	if err := store.deleteChunk(chunkID); err != nil {
+		return err
+	}
+
+	seqMu.Lock()
+	delete(lastApplied, chunkID)
+	delete(lastCommitted, chunkID)
+	seqMu.Unlock()
+
+	log.Printf("deleted chunk %s", chunkID)
+	return nil
+}
+
+// chunkReportHandler lists every committed chunk on a healthy disk, with its
+// version, so the master can reconcile its replica map against what is
+// actually stored.
+func chunkReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := chunkReportResp{UUID: nodeID, Chunks: store.chunkIDs(), Versions: store.chunkVersions()}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// disksHandler shows per-disk health, capacity and chunk counts.
+func disksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(store.snapshot())
 }
 
 func SendPostJSONAndDecode(url string, payload, out any) error {
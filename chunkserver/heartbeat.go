@@ -2,17 +2,47 @@ package main
 
 import (
 	"log"
+	"net/http"
+	"sync/atomic"
 	"time"
 )
 
 const (
-	masterURL          = "http://master:8080"
 	heartbeatInterval  = 3 * time.Second
 	registerRetryDelay = 2 * time.Second
 )
 
+var inflightReads, inflightWrites atomic.Int64
+
+// trackInflight counts requests to h in counter while they are being served.
+func trackInflight(counter *atomic.Int64, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		counter.Add(1)
+		defer counter.Add(-1)
+		h(w, r)
+	}
+}
+
+// currentStats sums capacity over healthy disks and reads the load counters.
+func currentStats() nodeStats {
+	st := nodeStats{
+		InflightReads:  inflightReads.Load(),
+		InflightWrites: inflightWrites.Load(),
+	}
+	for _, d := range store.snapshot() {
+		if !d.Healthy {
+			continue
+		}
+		st.TotalBytes += d.TotalBytes
+		st.FreeBytes += d.FreeBytes
+		st.UsedBytes += d.UsedBytes
+		st.ChunkCount += d.Chunks
+	}
+	return st
+}
+
 func startRegistration(port string) {
-	payload := RegisterRequest{Port: port}
+	payload := RegisterRequest{Port: port, Addr: serverAddr, UUID: nodeID, Incarnation: incarnation, Rack: rack, GRPCAddr: grpcAddr}
 
 	for {
 		err := sendPostJSON(masterURL+"/register", payload)
@@ -32,12 +62,13 @@ func startHeartbeats(port string, stopChan <-chan struct{}) {
 		for {
 			select {
 			case <-ticker.C:
-				hb := HeartbeatRequest{Port: port}
+				hb := HeartbeatRequest{Port: port, Addr: serverAddr, UUID: nodeID, Incarnation: incarnation, Rack: rack, GRPCAddr: grpcAddr, nodeStats: currentStats(), Versions: store.takeChanged()}
 				err := sendPostJSON(masterURL+"/heartbeat", hb)
 				if err != nil {
+					store.keepChanged(hb.Versions)
 					log.Printf("heartbeat error: %v", err)
 				} else {
-					log.Printf("\033[31mheartbeat sent:\033[0m from %s\n", port)
+					log.Printf("\033[31mheartbeat sent:\033[0m from %s\n", serverAddr)
 				}
 			case <-stopChan:
 				ticker.Stop()
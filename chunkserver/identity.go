@@ -0,0 +1,66 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const nodeIDFile = "chunkserver.id"
+
+var (
+	nodeID      string // persistent, survives restarts as long as the data dir does
+	incarnation string // random per process, lets the master spot two processes on one address
+)
+
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// loadOrCreateNodeID reads the node UUID from the first disk that has one and
+// copies it to any disk that doesn't. A fresh UUID is written only when no
+// disk has one (first start, or every disk replaced).
+func loadOrCreateNodeID(disks []*disk) (string, error) {
+	id := ""
+	var missing []string
+	for _, d := range disks {
+		path := filepath.Join(d.Dir, nodeIDFile)
+		b, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		got := strings.TrimSpace(string(b))
+		if got == "" {
+			missing = append(missing, path)
+			continue
+		}
+		if id == "" {
+			id = got
+		} else if got != id {
+			log.Printf("chunk-server: %s has node id %s, using %s", path, got, id)
+		}
+	}
+
+	if id == "" {
+		id = newUUID()
+	}
+	for _, path := range missing {
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, []byte(id+"\n"), 0644); err != nil {
+			return "", err
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return "", err
+		}
+	}
+	return id, nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -9,27 +10,56 @@ import (
 	"syscall"
 )
 
-var serverAddr string
+var (
+	serverAddr string
+	masterURL  string
+	rack       string
+	grpcAddr   string // advertised gRPC address, empty when disabled
+)
 
 func main() {
-	port := flag.String("port", "9001", "chunkserver port")
-	flag.Parse()
+	cfg, err := loadConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("chunk-server: config error: %v", err)
+	}
+
+	store, err = openStore(cfg.DataDirs)
+	if err != nil {
+		log.Fatalf("chunk-server: %v", err)
+	}
 
-	os.MkdirAll("data", 0755)
+	nodeID, err = loadOrCreateNodeID(store.healthyDisks())
+	if err != nil {
+		log.Fatalf("chunk-server: cannot load node id: %v", err)
+	}
+	incarnation = newUUID()
+	log.Printf("chunk-server: node id %s", nodeID)
 
-	addr := ":" + *port
+	addr := ":" + cfg.Port
 
-	serverAddr = "localhost:" + *port
+	serverAddr = cfg.Advertise
+	masterURL = cfg.MasterURL
+	rack = cfg.Rack
+	grpcAddr = cfg.GRPCAdvertise
 
 	srv := setupServer(addr)
 	startHTTPServer(srv)
 
+	grpcSrv, err := startGRPCServer(cfg.GRPCPort)
+	if err != nil {
+		log.Fatalf("chunk-server: %v", err)
+	}
+
 	// Register with master
-	startRegistration(*port)
+	startRegistration(cfg.Port)
 
 	// Heartbeats
 	stopHeartbeat := make(chan struct{})
-	startHeartbeats(*port, stopHeartbeat)
+	startHeartbeats(cfg.Port, stopHeartbeat)
+	go store.probeDisks(stopHeartbeat)
 
 	// Graceful shutdown
 	stop := make(chan os.Signal, 1)
@@ -38,6 +68,9 @@ func main() {
 
 	log.Println("chunk-server shutting down...")
 	close(stopHeartbeat)
+	if grpcSrv != nil {
+		grpcSrv.Stop()
+	}
 	srv.Close()
 	fmt.Println("chunk-server: shutdown complete")
 
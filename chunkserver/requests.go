@@ -1,9 +1,43 @@
 package main
 
 type RegisterRequest struct {
-	Port string `json:"port"`
+	Port        string `json:"port"`
+	Addr        string `json:"addr"`
+	UUID        string `json:"uuid"`
+	Incarnation string `json:"incarnation"`
+	Rack        string `json:"rack,omitempty"` // failure domain (rack or zone)
+	GRPCAddr    string `json:"grpc_addr,omitempty"`
 }
 
 type HeartbeatRequest struct {
-	Port string `json:"port"`
+	Port        string `json:"port"`
+	Addr        string `json:"addr"`
+	UUID        string `json:"uuid"`
+	Incarnation string `json:"incarnation"`
+	Rack        string `json:"rack,omitempty"` // failure domain (rack or zone)
+	GRPCAddr    string `json:"grpc_addr,omitempty"`
+	nodeStats
+	Versions map[string]uint64 `json:"versions,omitempty"` // chunks committed since the last heartbeat
+}
+
+// nodeStats is the capacity and load snapshot sent with every heartbeat.
+type nodeStats struct {
+	TotalBytes     uint64 `json:"total_bytes"`
+	FreeBytes      uint64 `json:"free_bytes"`
+	UsedBytes      uint64 `json:"used_bytes"`
+	ChunkCount     int    `json:"chunk_count"`
+	InflightReads  int64  `json:"inflight_reads"`
+	InflightWrites int64  `json:"inflight_writes"`
+}
+
+type chunkReportResp struct {
+	UUID     string            `json:"uuid"`
+	Chunks   []string          `json:"chunks"`
+	Versions map[string]uint64 `json:"versions"`
+}
+
+type lostChunksReq struct {
+	Addr   string   `json:"addr"`
+	UUID   string   `json:"uuid"`
+	Chunks []string `json:"chunks"`
 }
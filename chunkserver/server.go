@@ -8,13 +8,16 @@ import (
 func setupServer(addr string) *http.Server {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/hello", helloHandler(addr))
-	mux.HandleFunc("/write_chunk", writeChunkHandler)
-	mux.HandleFunc("/read_chunk", readChunkHandler)
-	mux.HandleFunc("/copy_chunk", copyChunkHandler)
-	mux.HandleFunc("/receive_chunk", receiveChunkHandler)
-	mux.HandleFunc("/write_primary", writePrimaryHandler)
-	mux.HandleFunc("/apply_write", applyWriteHandler)
+	mux.HandleFunc("/write_chunk", trackInflight(&inflightWrites, writeChunkHandler))
+	mux.HandleFunc("/read_chunk", trackInflight(&inflightReads, readChunkHandler))
+	mux.HandleFunc("/copy_chunk", trackInflight(&inflightReads, copyChunkHandler))
+	mux.HandleFunc("/receive_chunk", trackInflight(&inflightWrites, receiveChunkHandler))
+	mux.HandleFunc("/write_primary", trackInflight(&inflightWrites, writePrimaryHandler))
+	mux.HandleFunc("/apply_write", trackInflight(&inflightWrites, applyWriteHandler))
 	mux.HandleFunc("/commit", commitHandler)
+	mux.HandleFunc("/delete_chunk", trackInflight(&inflightWrites, deleteChunkHandler))
+	mux.HandleFunc("/chunk_report", chunkReportHandler)
+	mux.HandleFunc("/disks", disksHandler)
 
 	return &http.Server{
 		Addr:    addr,
@@ -0,0 +1,523 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	diskReserveBytes  = 16 * 1024 * 1024 // keep this much free on every disk
+	diskProbeInterval = 10 * time.Second
+	probeFile         = ".probe"
+)
+
+// disk is one configured storage directory.
+type disk struct {
+	Dir        string `json:"dir"`
+	Healthy    bool   `json:"healthy"`
+	Error      string `json:"error,omitempty"`
+	TotalBytes uint64 `json:"total_bytes"`
+	FreeBytes  uint64 `json:"free_bytes"`
+	UsedBytes  uint64 `json:"used_bytes"` // bytes held in chunk files
+	Chunks     int    `json:"chunks"`
+}
+
+// chunkStore spreads chunks over several disks and tracks where each one lives.
+type chunkStore struct {
+	mu      sync.Mutex
+	disks   []*disk
+	index   map[string]*disk // committed chunkID -> disk
+	pending map[string]*disk // placement picked for a chunk not yet committed
+	sizes   map[string]int64 // committed chunkID -> bytes on disk
+
+	// versions holds each committed chunk's version, 0 for chunks stored
+	// before versions existed; changed holds the ones committed since the
+	// last heartbeat
+	versions map[string]uint64
+	changed  map[string]uint64
+
+	// swap is held for writing while a chunk's data and checksum files are
+	// renamed or removed, and for reading while openRange opens them, so a
+	// read never pairs one write's data with another's checksums
+	swap sync.RWMutex
+}
+
+var store *chunkStore
+
+// openStore prepares every directory and indexes the chunks already on them.
+// A directory that can't be used is kept but marked unhealthy.
+func openStore(dirs []string) (*chunkStore, error) {
+	s := &chunkStore{
+		index:    make(map[string]*disk),
+		pending:  make(map[string]*disk),
+		sizes:    make(map[string]int64),
+		versions: make(map[string]uint64),
+		changed:  make(map[string]uint64),
+	}
+	for _, dir := range dirs {
+		d := &disk{Dir: dir, Healthy: true}
+		s.disks = append(s.disks, d)
+
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			s.fail(d, err)
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			s.fail(d, err)
+			continue
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() || !strings.HasSuffix(name, ".bin") {
+				continue
+			}
+			id := strings.TrimSuffix(name, ".bin")
+			if prev, dup := s.index[id]; dup {
+				log.Printf("chunk-server: chunk %s found on %s and %s, keeping %s", id, prev.Dir, dir, prev.Dir)
+				continue
+			}
+			var size int64
+			if info, err := e.Info(); err == nil {
+				size = info.Size()
+			}
+			s.index[id] = d
+			s.sizes[id] = size
+			s.versions[id] = readVersion(verPath(filepath.Join(dir, name)))
+			d.Chunks++
+			d.UsedBytes += uint64(size)
+		}
+		s.refreshStats(d)
+	}
+
+	if len(s.healthyDisks()) == 0 {
+		return nil, fmt.Errorf("no usable data directory in %v", dirs)
+	}
+	return s, nil
+}
+
+// fail marks d unhealthy. Callers must hold s.mu or own s exclusively.
+func (s *chunkStore) fail(d *disk, err error) {
+	d.Healthy = false
+	d.Error = err.Error()
+	log.Printf("chunk-server: disk %s failed: %v", d.Dir, err)
+}
+
+func (s *chunkStore) healthyDisks() []*disk {
+	var out []*disk
+	for _, d := range s.disks {
+		if d.Healthy {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func (s *chunkStore) refreshStats(d *disk) {
+	total, free, err := diskUsage(d.Dir)
+	if err != nil {
+		return
+	}
+	d.TotalBytes = total
+	d.FreeBytes = free
+}
+
+// chunkPath returns the committed file for chunkID, or "" if we don't hold it.
+func (s *chunkStore) chunkPath(chunkID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.index[chunkID]
+	if !ok || !d.Healthy {
+		return ""
+	}
+	return filepath.Join(d.Dir, chunkID+".bin")
+}
+
+// chunkSize returns the committed size of chunkID, if we hold it.
+func (s *chunkStore) chunkSize(chunkID string) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.index[chunkID]
+	if !ok || !d.Healthy {
+		return 0, false
+	}
+	return s.sizes[chunkID], true
+}
+
+// diskOf returns the configured disk holding path, or nil.
+func (s *chunkStore) diskOf(path string) *disk {
+	for _, d := range s.disks {
+		if filepath.Dir(path) == filepath.Clean(d.Dir) {
+			return d
+		}
+	}
+	return nil
+}
+
+// diskFor returns the disk chunkID lives on, picking the healthy disk with the
+// most free space for a chunk we don't hold yet. The choice sticks until the
+// chunk is committed so temp files and the final file share a filesystem.
+func (s *chunkStore) diskFor(chunkID string) (*disk, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if d, ok := s.index[chunkID]; ok && d.Healthy {
+		return d, nil
+	}
+	if d, ok := s.pending[chunkID]; ok && d.Healthy {
+		return d, nil
+	}
+
+	var best *disk
+	for _, d := range s.disks {
+		if !d.Healthy || (d.TotalBytes > 0 && d.FreeBytes < diskReserveBytes) {
+			continue
+		}
+		if best == nil || d.FreeBytes > best.FreeBytes {
+			best = d
+		}
+	}
+	if best == nil {
+		return nil, errors.New("no healthy disk with free space")
+	}
+	s.pending[chunkID] = best
+	return best, nil
+}
+
+// tmpPath names the staging file for write seq of chunkID on disk d.
+func (d *disk) tmpPath(chunkID string, seq uint64) string {
+	return filepath.Join(d.Dir, fmt.Sprintf("%s.%d.tmp", chunkID, seq))
+}
+
+func (d *disk) finalPath(chunkID string) string {
+	return filepath.Join(d.Dir, chunkID+".bin")
+}
+
+// verPath names the sidecar holding the version of the chunk at path: the
+// master's count of committed writes, which tells a current copy from one
+// that missed writes while its node was away.
+func verPath(path string) string {
+	return path + ".ver"
+}
+
+func writeVersion(path string, version uint64) error {
+	return os.WriteFile(path, []byte(strconv.FormatUint(version, 10)), 0644)
+}
+
+// readVersion returns the version stored at path, 0 if there is none.
+func readVersion(path string) uint64 {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	v, _ := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+	return v
+}
+
+// committed records that chunkID now has size bytes at version on d.
+func (s *chunkStore) committed(chunkID string, d *disk, size int64, version uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.pending, chunkID)
+	s.versions[chunkID] = version
+	s.changed[chunkID] = version
+	if prev, ok := s.index[chunkID]; ok {
+		prev.Chunks--
+		prev.UsedBytes -= uint64(s.sizes[chunkID])
+	}
+	s.index[chunkID] = d
+	s.sizes[chunkID] = size
+	d.Chunks++
+	d.UsedBytes += uint64(size)
+	if d.FreeBytes > uint64(size) {
+		d.FreeBytes -= uint64(size)
+	}
+}
+
+// writeChunk stores a full chunk at version, picking a disk if it's new. The
+// data is staged next to the final file and renamed into place, so a
+// transfer that breaks off never replaces a good copy.
+func (s *chunkStore) writeChunk(chunkID string, r io.Reader, version uint64) (int64, error) {
+	d, err := s.diskFor(chunkID)
+	if err != nil {
+		return 0, err
+	}
+	tmp := filepath.Join(d.Dir, chunkID+".recv.tmp")
+	n, err := s.writeFile(d, tmp, r, version)
+	if err != nil {
+		return n, err
+	}
+	if err := s.install(d, tmp, chunkID); err != nil {
+		return n, err
+	}
+	s.committed(chunkID, d, n, version)
+	return n, nil
+}
+
+// writeFile copies r into path on d, with its block checksums and version
+// next to it. A partly written file is removed.
+func (s *chunkStore) writeFile(d *disk, path string, r io.Reader, version uint64) (int64, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		s.checkErr(d, err)
+		return 0, err
+	}
+	var sum blockSummer
+	n, err := io.Copy(io.MultiWriter(f, &sum), r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = writeChecksums(crcPath(path), sum.finish())
+	}
+	if err == nil {
+		err = writeVersion(verPath(path), version)
+	}
+	if err != nil {
+		removeFile(path)
+		s.checkErr(d, err)
+		return n, err
+	}
+	return n, nil
+}
+
+// install renames the staged file tmp, its checksums and its version into
+// place as chunkID's committed copy.
+func (s *chunkStore) install(d *disk, tmp, chunkID string) error {
+	final := d.finalPath(chunkID)
+	s.swap.Lock()
+	defer s.swap.Unlock()
+	if err := os.Rename(crcPath(tmp), crcPath(final)); err != nil {
+		s.checkErr(d, err)
+		return err
+	}
+	if err := os.Rename(verPath(tmp), verPath(final)); err != nil {
+		s.checkErr(d, err)
+		return err
+	}
+	if err := os.Rename(tmp, final); err != nil {
+		s.checkErr(d, err)
+		return err
+	}
+	return nil
+}
+
+// removeFile deletes a chunk or staging file, its checksums and its version.
+func removeFile(path string) error {
+	os.Remove(crcPath(path))
+	os.Remove(verPath(path))
+	return os.Remove(path)
+}
+
+// deleteChunk removes a committed chunk. Deleting a chunk we don't hold is not an error.
+func (s *chunkStore) deleteChunk(chunkID string) error {
+	s.mu.Lock()
+	d, ok := s.index[chunkID]
+	if !ok {
+		s.mu.Unlock()
+		return nil
+	}
+	delete(s.index, chunkID)
+	size := s.sizes[chunkID]
+	delete(s.sizes, chunkID)
+	delete(s.versions, chunkID)
+	delete(s.changed, chunkID)
+	d.Chunks--
+	d.UsedBytes -= uint64(size)
+	s.mu.Unlock()
+
+	s.swap.Lock()
+	err := removeFile(d.finalPath(chunkID))
+	s.swap.Unlock()
+	if err != nil && !os.IsNotExist(err) {
+		s.checkErr(d, err)
+		return err
+	}
+	return nil
+}
+
+// chunkIDs lists every chunk on a healthy disk.
+func (s *chunkStore) chunkIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, 0, len(s.index))
+	for id, d := range s.index {
+		if d.Healthy {
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// chunkVersions maps every chunk on a healthy disk to its version.
+func (s *chunkStore) chunkVersions() map[string]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]uint64, len(s.index))
+	for id, d := range s.index {
+		if d.Healthy {
+			out[id] = s.versions[id]
+		}
+	}
+	return out
+}
+
+// version returns chunkID's version, 0 if unknown.
+func (s *chunkStore) version(chunkID string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.versions[chunkID]
+}
+
+// takeChanged returns the versions committed since the last call. A
+// heartbeat that fails hands them back with keepChanged.
+func (s *chunkStore) takeChanged() map[string]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.changed) == 0 {
+		return nil
+	}
+	out := s.changed
+	s.changed = make(map[string]uint64)
+	return out
+}
+
+// keepChanged puts versions back for the next heartbeat, unless the chunk
+// changed again or went away meanwhile.
+func (s *chunkStore) keepChanged(versions map[string]uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, v := range versions {
+		if _, again := s.changed[id]; again {
+			continue
+		}
+		if _, ok := s.index[id]; ok {
+			s.changed[id] = v
+		}
+	}
+}
+
+func (s *chunkStore) snapshot() []disk {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]disk, 0, len(s.disks))
+	for _, d := range s.disks {
+		out = append(out, *d)
+	}
+	return out
+}
+
+// checkErr takes d out of service if err looks like a device failure rather
+// than a missing file or a full disk.
+func (s *chunkStore) checkErr(d *disk, err error) {
+	if d == nil || err == nil || os.IsNotExist(err) || errors.Is(err, syscall.ENOSPC) {
+		return
+	}
+	if errors.Is(err, syscall.EIO) || errors.Is(err, syscall.EROFS) || errors.Is(err, syscall.ENXIO) {
+		s.takeOffline(d, err)
+	}
+}
+
+// takeOffline marks d failed and reports every chunk it held to the master as lost.
+func (s *chunkStore) takeOffline(d *disk, err error) {
+	s.mu.Lock()
+	if !d.Healthy {
+		s.mu.Unlock()
+		return
+	}
+	s.fail(d, err)
+	var lost []string
+	for id, cd := range s.index {
+		if cd == d {
+			lost = append(lost, id)
+			delete(s.index, id)
+			delete(s.sizes, id)
+		}
+	}
+	for id, pd := range s.pending {
+		if pd == d {
+			delete(s.pending, id)
+		}
+	}
+	d.Chunks = 0
+	d.UsedBytes = 0
+	s.mu.Unlock()
+
+	sort.Strings(lost)
+	go reportLostChunks(lost)
+}
+
+// probeDisks periodically checks every disk with a small write/read and
+// refreshes capacity numbers.
+func (s *chunkStore) probeDisks(stop <-chan struct{}) {
+	ticker := time.NewTicker(diskProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, d := range s.disks {
+				s.mu.Lock()
+				healthy := d.Healthy
+				s.mu.Unlock()
+				if !healthy {
+					continue
+				}
+				// a full disk can't take the probe file but is still
+				// serving its chunks; only refresh its numbers
+				if err := probe(d.Dir); err != nil && !errors.Is(err, syscall.ENOSPC) {
+					s.takeOffline(d, err)
+					continue
+				}
+				s.mu.Lock()
+				s.refreshStats(d)
+				s.mu.Unlock()
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func probe(dir string) error {
+	path := filepath.Join(dir, probeFile)
+	want := []byte(time.Now().Format(time.RFC3339Nano))
+	if err := os.WriteFile(path, want, 0644); err != nil {
+		return err
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if string(got) != string(want) {
+		return errors.New("probe read back different data")
+	}
+	return os.Remove(path)
+}
+
+func reportLostChunks(lost []string) {
+	if len(lost) == 0 {
+		return
+	}
+	req := lostChunksReq{Addr: serverAddr, UUID: nodeID, Chunks: lost}
+	for attempt := 1; attempt <= 5; attempt++ {
+		err := sendPostJSON(masterURL+"/report_lost", req)
+		if err == nil {
+			log.Printf("chunk-server: reported %d lost chunks to master", len(lost))
+			return
+		}
+		log.Printf("chunk-server: lost chunk report failed: %v | retrying...", err)
+		time.Sleep(time.Duration(attempt) * registerRetryDelay)
+	}
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The benchmarks need a running cluster, e.g. a master and three
+// chunkservers started as in the README:
+//
+//	GFS_BENCH_MASTER=localhost:8080 go test -run=^$ -bench=. ./client
+//
+// GFS_BENCH_CHUNK_SIZE must match the master's -chunk-size if it isn't the
+// default, and GFS_BENCH_SIZE sets the file size (default 64MiB).
+func benchClient(b *testing.B, parallel int) *Client {
+	master := os.Getenv("GFS_BENCH_MASTER")
+	if master == "" {
+		b.Skip("GFS_BENCH_MASTER not set")
+	}
+	chunkSize := int64(DefaultChunkSize)
+	if v := os.Getenv("GFS_BENCH_CHUNK_SIZE"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			b.Fatalf("GFS_BENCH_CHUNK_SIZE: %v", err)
+		}
+		chunkSize = n
+	}
+	c, err := New(Config{MasterURL: master, ChunkSize: chunkSize, Parallelism: parallel})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return c
+}
+
+func benchData(b *testing.B) []byte {
+	size := 64 << 20
+	if v := os.Getenv("GFS_BENCH_SIZE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			b.Fatalf("GFS_BENCH_SIZE: %v", err)
+		}
+		size = n
+	}
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	return data
+}
+
+// benchName returns a file name no earlier run has used.
+func benchName(b *testing.B, i int) string {
+	name := strings.NewReplacer("/", "-", "=", "").Replace(b.Name())
+	return fmt.Sprintf("%s-%d-%d", name, time.Now().UnixNano(), i)
+}
+
+var parallelisms = []int{1, 2, 4, 8}
+
+func BenchmarkUpload(b *testing.B) {
+	for _, p := range parallelisms {
+		b.Run(fmt.Sprintf("parallel=%d", p), func(b *testing.B) {
+			c := benchClient(b, p)
+			data := benchData(b)
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := c.Upload(context.Background(), benchName(b, i), data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkUploadFrom(b *testing.B) {
+	for _, p := range parallelisms {
+		b.Run(fmt.Sprintf("parallel=%d", p), func(b *testing.B) {
+			c := benchClient(b, p)
+			data := benchData(b)
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.UploadFrom(context.Background(), benchName(b, i), bytes.NewReader(data)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDownload(b *testing.B) {
+	for _, p := range parallelisms {
+		b.Run(fmt.Sprintf("parallel=%d", p), func(b *testing.B) {
+			c := benchClient(b, p)
+			data := benchData(b)
+			name := benchName(b, 0)
+			if err := c.Upload(context.Background(), name, data); err != nil {
+				b.Fatal(err)
+			}
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				n, err := c.DownloadTo(context.Background(), name, io.Discard)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if n != int64(len(data)) {
+					b.Fatalf("read %d bytes, want %d", n, len(data))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// locateBatch is how many chunk indexes one /file_chunks lookup resolves.
+const locateBatch = 32
+
+// cache remembers what the master told us: which chunk holds each index of
+// a file, where each chunk lives, and who holds each chunk's lease. A file's
+// chunk indexes and the locations expire after the client's CacheTTL, since
+// files can be deleted or renamed; primaries expire with their lease.
+// Locations and primaries are also dropped as soon as a chunkserver turns
+// out not to match them, and file entries when this client deletes or
+// renames anything.
+type cache struct {
+	ttl time.Duration // 0 disables caching
+
+	mu        sync.Mutex
+	files     map[string]*fileEntry   // file -> its chunk IDs by index
+	locations map[string]locEntry     // chunk ID -> replicas
+	primaries map[string]primaryEntry // chunk ID -> lease holder
+}
+
+type fileEntry struct {
+	ids     map[int64]string
+	expires time.Time
+}
+
+type locEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+type primaryEntry struct {
+	addr    string
+	expires time.Time
+}
+
+func newCache(ttl time.Duration) *cache {
+	return &cache{
+		ttl:       ttl,
+		files:     make(map[string]*fileEntry),
+		locations: make(map[string]locEntry),
+		primaries: make(map[string]primaryEntry),
+	}
+}
+
+func (c *cache) chunkAt(file string, index int64) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.files[file]
+	if !ok || time.Now().After(e.expires) {
+		return "", false
+	}
+	id, ok := e.ids[index]
+	return id, ok
+}
+
+func (c *cache) replicas(chunkID string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.locations[chunkID]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.addrs, true
+}
+
+func (c *cache) primary(chunkID string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.primaries[chunkID]
+	if !ok || time.Now().After(e.expires) {
+		return "", false
+	}
+	return e.addr, true
+}
+
+// putChunk records that index of file is chunkID, stored on addrs.
+func (c *cache) putChunk(file string, index int64, chunkID string, addrs []string) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	e, ok := c.files[file]
+	if !ok || now.After(e.expires) {
+		e = &fileEntry{ids: make(map[int64]string), expires: now.Add(c.ttl)}
+		c.files[file] = e
+	}
+	e.ids[index] = chunkID
+	c.locations[chunkID] = locEntry{addrs: addrs, expires: time.Now().Add(c.ttl)}
+}
+
+func (c *cache) putLocations(chunkID string, addrs []string) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.locations[chunkID] = locEntry{addrs: addrs, expires: time.Now().Add(c.ttl)}
+}
+
+// putPrimary caches a lease holder until shortly before the lease ends.
+func (c *cache) putPrimary(chunkID, addr string, leaseSeconds int64) {
+	if c.ttl <= 0 || leaseSeconds <= 1 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.primaries[chunkID] = primaryEntry{addr: addr, expires: time.Now().Add(time.Duration(leaseSeconds-1) * time.Second)}
+}
+
+// invalidate forgets the locations and primary of chunkID, e.g. after one of
+// its chunkservers rejected a request.
+func (c *cache) invalidate(chunkID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.locations, chunkID)
+	delete(c.primaries, chunkID)
+}
+
+// forgetFiles drops every file's chunk indexes, after a delete or rename
+// that may have moved any of them.
+func (c *cache) forgetFiles() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.files)
+}
+
+// fileChunks fetches the chunks of name with indexes [start, start+count)
+// from the master and caches them. It also returns the file's chunk count.
+func (c *Client) fileChunks(ctx context.Context, name string, start, count int64) ([]ChunkInfo, int64, error) {
+	var resp struct {
+		Total  int64       `json:"total"`
+		Chunks []ChunkInfo `json:"chunks"`
+	}
+	req := c.withHint(map[string]any{"file": name, "start": start, "count": count})
+	if err := c.postJSON(ctx, "/file_chunks", req, &resp); err != nil {
+		return nil, 0, err
+	}
+	for _, fc := range resp.Chunks {
+		c.cache.putChunk(name, fc.Index, fc.ChunkID, fc.Locations)
+	}
+	return resp.Chunks, resp.Total, nil
+}
+
+// chunkAt returns the ID of chunk index of file name, resolving it and the
+// following chunks in one batch on a cache miss. Indexes past the end of the
+// file fail with ErrNotFound.
+func (c *Client) chunkAt(ctx context.Context, name string, index int64) (string, error) {
+	if id, ok := c.cache.chunkAt(name, index); ok {
+		return id, nil
+	}
+	batch := int64(locateBatch)
+	if c.cache.ttl <= 0 {
+		batch = 1
+	}
+	chunks, total, err := c.fileChunks(ctx, name, index, batch)
+	if err != nil {
+		return "", err
+	}
+	if len(chunks) == 0 || chunks[0].Index != index {
+		return "", fmt.Errorf("%w: %s has %d chunks, no index %d", ErrNotFound, name, total, index)
+	}
+	return chunks[0].ChunkID, nil
+}
@@ -0,0 +1,272 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Allocation is the master's answer to an allocate request: one chunk ID and
+// replica list per chunk, in file order, and the chunk size they hold.
+type Allocation struct {
+	ChunkIDs  []string   `json:"chunk_ids"`
+	Locations [][]string `json:"locations"`
+	ChunkSize int64      `json:"chunk_size"`
+}
+
+// Primary is a chunk's lease holder as reported by the master.
+type Primary struct {
+	Primary      string   `json:"primary"`
+	LeaseSeconds int64    `json:"lease_seconds"`
+	Replicas     []string `json:"replicas"`
+	Version      uint64   `json:"version,omitempty"`
+}
+
+// FileInfo describes a file as the master knows it. Length counts committed
+// bytes only; chunks allocated but not yet written add nothing to it.
+type FileInfo struct {
+	Name      string      `json:"file"`
+	Length    int64       `json:"length"`
+	ChunkSize int64       `json:"chunk_size"`
+	Chunks    []ChunkInfo `json:"chunks"`
+}
+
+// ChunkInfo is one chunk of a file, how many of its bytes are valid, and the
+// chunkservers holding it.
+type ChunkInfo struct {
+	Index     int64    `json:"index"`
+	ChunkID   string   `json:"chunk_id"`
+	Length    int64    `json:"length"`
+	Locations []string `json:"locations"`
+}
+
+// FileStat is a file's size without its chunk list.
+type FileStat struct {
+	Name        string `json:"file"`
+	Length      int64  `json:"length"`
+	ChunkSize   int64  `json:"chunk_size"`
+	Chunks      int64  `json:"chunks"`
+	Replication int    `json:"replication"`
+}
+
+// Lookup returns name's chunks in order with their replicas. It fails with
+// ErrNotFound if the file doesn't exist.
+func (c *Client) Lookup(ctx context.Context, name string) (*FileInfo, error) {
+	var info FileInfo
+	if err := c.postJSON(ctx, "/lookup", c.withHint(map[string]any{"file": name}), &info); err != nil {
+		return nil, err
+	}
+	for _, ch := range info.Chunks {
+		c.cache.putChunk(name, ch.Index, ch.ChunkID, ch.Locations)
+	}
+	return &info, nil
+}
+
+// Stat returns name's length and chunk count. It fails with ErrNotFound if
+// the file doesn't exist.
+func (c *Client) Stat(ctx context.Context, name string) (*FileStat, error) {
+	var st FileStat
+	if err := c.postJSON(ctx, "/stat", map[string]string{"file": name}, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// Allocate asks the master for enough chunks to hold size more bytes of file.
+// It is not retried: if the reply is lost the chunks may exist anyway, and
+// allocating again would add more. The master refuses it if its chunk size
+// isn't the client's, since the data would be split at the wrong offsets.
+// Size 0 creates file, empty, if it doesn't exist and allocates nothing.
+func (c *Client) Allocate(ctx context.Context, file string, size int64) (*Allocation, error) {
+	var alloc Allocation
+	req := map[string]any{"file": file, "size_bytes": size, "chunk_size": c.chunkSize}
+	if err := c.postJSONOnce(ctx, "/allocate", req, &alloc); err != nil {
+		return nil, err
+	}
+	if err := c.checkChunkSize(file, alloc.ChunkSize); err != nil {
+		return nil, err
+	}
+	if len(alloc.ChunkIDs) == 0 && size > 0 {
+		return nil, fmt.Errorf("gfs: /allocate: no chunk ids returned")
+	}
+	for i, id := range alloc.ChunkIDs {
+		if i < len(alloc.Locations) {
+			c.cache.putLocations(id, alloc.Locations[i])
+		}
+	}
+	return &alloc, nil
+}
+
+// ChunkLocations returns the chunkservers holding chunkID, from the cache
+// when it has them.
+func (c *Client) ChunkLocations(ctx context.Context, chunkID string) ([]string, error) {
+	if locs, ok := c.cache.replicas(chunkID); ok {
+		return locs, nil
+	}
+	var resp struct {
+		Locations []string `json:"locations"`
+	}
+	if err := c.postJSON(ctx, "/chunk_locations", c.withHint(map[string]any{"chunk_id": chunkID}), &resp); err != nil {
+		return nil, err
+	}
+	c.cache.putLocations(chunkID, resp.Locations)
+	return resp.Locations, nil
+}
+
+// PrimaryFor returns chunkID's current primary, asking the master to assign
+// one if no lease is held. A primary is cached until its lease runs out.
+func (c *Client) PrimaryFor(ctx context.Context, chunkID string) (string, error) {
+	if addr, ok := c.cache.primary(chunkID); ok {
+		return addr, nil
+	}
+	var p Primary
+	req := map[string]string{"chunk_id": chunkID}
+	if err := c.postJSON(ctx, "/get_primary", req, &p); err != nil {
+		return "", err
+	}
+	if p.Primary == "" {
+		if err := c.postJSON(ctx, "/assign_primary", req, &p); err != nil {
+			return "", err
+		}
+		if p.Primary == "" {
+			return "", ErrNoPrimary
+		}
+	}
+	c.cache.putPrimary(chunkID, p.Primary, p.LeaseSeconds)
+	c.cache.putLocations(chunkID, p.Replicas)
+	return p.Primary, nil
+}
+
+// WriteChunk replaces the contents of chunkID with data through its primary,
+// which replicates it to the other replicas. A failed write is retried with a
+// freshly looked-up primary.
+func (c *Client) WriteChunk(ctx context.Context, chunkID string, data []byte) error {
+	err := c.withRetry(ctx, func() error {
+		primary, err := c.PrimaryFor(ctx, chunkID)
+		if err != nil {
+			return err
+		}
+		err = c.postChunk(ctx, "http://"+primary+"/write_primary", chunkID, data)
+		if err != nil {
+			// the lease or replica set may have changed under us
+			c.cache.invalidate(chunkID)
+		}
+		return err
+	})
+	if err != nil {
+		return &ChunkError{ChunkID: chunkID, Op: "write", Err: err}
+	}
+	return nil
+}
+
+// postChunk sends data as the raw body of a chunk write, with the chunk ID
+// in a header, instead of base64 inside JSON.
+func (c *Client) postChunk(ctx context.Context, url, chunkID string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-Chunk-Id", chunkID)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError("/write_primary", url, resp)
+	}
+	return nil
+}
+
+// ReadChunk returns the contents of chunkID.
+func (c *Client) ReadChunk(ctx context.Context, chunkID string) ([]byte, error) {
+	return c.ReadChunkRange(ctx, chunkID, 0, 0)
+}
+
+// ReadChunkRange returns n bytes of chunkID starting at off, or everything
+// from off when n is 0. Only the requested bytes are transferred; fewer come
+// back if the chunk ends first. Replicas are tried nearest and fastest
+// first, with a hedged request to the next one when the first is slow. If
+// every cached replica fails, locations are fetched from the master again
+// once.
+func (c *Client) ReadChunkRange(ctx context.Context, chunkID string, off, n int64) ([]byte, error) {
+	_, cached := c.cache.replicas(chunkID)
+	for {
+		locs, err := c.ChunkLocations(ctx, chunkID)
+		if err != nil {
+			return nil, &ChunkError{ChunkID: chunkID, Op: "read", Err: err}
+		}
+
+		data, err := c.readHedged(ctx, chunkID, locs, off, n)
+		if err == nil {
+			return data, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !cached {
+			return nil, &ChunkError{ChunkID: chunkID, Op: "read", Err: err}
+		}
+		cached = false
+	}
+}
+
+// openReplica asks addr for the range and returns once the response headers
+// arrive; the caller reads and closes the body.
+func (c *Client) openReplica(ctx context.Context, addr, chunkID string, off, n int64) (*http.Response, error) {
+	q := url.Values{"chunk_id": {chunkID}}
+	if off > 0 {
+		q.Set("offset", strconv.FormatInt(off, 10))
+	}
+	if n > 0 {
+		q.Set("length", strconv.FormatInt(n, 10))
+	}
+	u := "http://" + addr + "/read_chunk?" + q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, statusError("/read_chunk", u, resp)
+	}
+	return resp, nil
+}
+
+// Upload allocates chunks for data under name and writes up to Parallelism
+// of them at once.
+func (c *Client) Upload(ctx context.Context, name string, data []byte) error {
+	alloc, err := c.Allocate(ctx, name, int64(len(data)))
+	if err != nil {
+		return err
+	}
+	return c.writeParallel(ctx, alloc.ChunkIDs, data)
+}
+
+// Download looks up file name and returns its contents, reading up to
+// Parallelism chunks at once. It fails with ErrNotFound if the file doesn't
+// exist.
+func (c *Client) Download(ctx context.Context, name string) ([]byte, error) {
+	info, err := c.Lookup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkChunkSize(name, info.ChunkSize); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.Grow(int(info.Length))
+	if _, err := c.readRange(ctx, name, 0, info.Length, &out); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,230 @@
+// Package client talks to a miniGFS master and its chunkservers over HTTP.
+//
+//	c, err := client.New(client.Config{MasterURL: "http://localhost:8080"})
+//	err = c.Upload(ctx, "report.csv", data)
+//	data, err = c.Download(ctx, "report.csv")
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultChunkSize matches the master's default -chunk-size.
+const DefaultChunkSize = 4 * 1024 * 1024
+
+// Config configures a Client. Zero fields take the defaults noted on each.
+type Config struct {
+	MasterURL  string        // default http://localhost:8080
+	Timeout    time.Duration // per HTTP request, default 30s
+	Retry      RetryPolicy
+	ChunkSize  int64        // must match the master's, default DefaultChunkSize
+	HTTPClient *http.Client // default a client with Timeout
+
+	// Parallelism is how many chunks Upload, Download and file copies
+	// transfer at once, default 4.
+	Parallelism int
+	// MemoryBudget caps the chunk data held in flight by parallel transfers,
+	// default Parallelism chunks. It is never less than one chunk.
+	MemoryBudget int64
+	// CacheTTL is how long chunk locations from the master are reused,
+	// default 1m; negative disables the metadata cache.
+	CacheTTL time.Duration
+
+	// Host and Rack say where this client runs, so the master lists nearby
+	// replicas first. Host is compared with the host part of chunkserver
+	// addresses and Rack with their -rack. Both are optional.
+	Host string
+	Rack string
+	// HedgeDelay is how long a read waits for a replica to start answering
+	// before asking another one too. The default, 0, derives it from the
+	// replica's observed latency; negative disables hedged reads.
+	HedgeDelay time.Duration
+}
+
+// RetryPolicy controls how often a failed call is retried. Only transport
+// errors and 5xx responses are retried; 4xx responses fail at once. Calls
+// that change the master's namespace or allocate chunks are never retried.
+type RetryPolicy struct {
+	MaxAttempts int           // including the first, default 3
+	Backoff     time.Duration // before the second attempt, doubled each time, default 200ms
+	MaxBackoff  time.Duration // default 5s
+}
+
+// Client is safe for concurrent use.
+type Client struct {
+	master    string
+	http      *http.Client
+	retry     RetryPolicy
+	chunkSize int64
+	parallel  int
+	budget    int64
+	cache     *cache
+	host      string
+	rack      string
+	hedge     time.Duration
+	replicas  *replicaStats
+}
+
+// New returns a Client for cfg.
+func New(cfg Config) (*Client, error) {
+	if cfg.MasterURL == "" {
+		cfg.MasterURL = "http://localhost:8080"
+	}
+	if !strings.HasPrefix(cfg.MasterURL, "http://") && !strings.HasPrefix(cfg.MasterURL, "https://") {
+		cfg.MasterURL = "http://" + cfg.MasterURL
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.ChunkSize == 0 {
+		cfg.ChunkSize = DefaultChunkSize
+	}
+	if cfg.Parallelism == 0 {
+		cfg.Parallelism = 4
+	}
+	if cfg.MemoryBudget == 0 {
+		cfg.MemoryBudget = int64(cfg.Parallelism) * cfg.ChunkSize
+	}
+	if cfg.ChunkSize < 0 || cfg.Timeout < 0 || cfg.Parallelism < 0 || cfg.MemoryBudget < 0 {
+		return nil, errors.New("gfs: chunk size, timeout, parallelism and memory budget must not be negative")
+	}
+	if cfg.Retry.MaxAttempts == 0 {
+		cfg.Retry.MaxAttempts = 3
+	}
+	if cfg.Retry.Backoff == 0 {
+		cfg.Retry.Backoff = 200 * time.Millisecond
+	}
+	if cfg.Retry.MaxBackoff == 0 {
+		cfg.Retry.MaxBackoff = 5 * time.Second
+	}
+	if cfg.CacheTTL == 0 {
+		cfg.CacheTTL = time.Minute
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: cfg.Timeout}
+	}
+	return &Client{
+		master:    strings.TrimRight(cfg.MasterURL, "/"),
+		http:      cfg.HTTPClient,
+		retry:     cfg.Retry,
+		chunkSize: cfg.ChunkSize,
+		parallel:  cfg.Parallelism,
+		budget:    cfg.MemoryBudget,
+		cache:     newCache(max(cfg.CacheTTL, 0)),
+		host:      cfg.Host,
+		rack:      cfg.Rack,
+		hedge:     cfg.HedgeDelay,
+		replicas:  newReplicaStats(),
+	}, nil
+}
+
+// withHint adds the client's locality hint to a request for chunk
+// locations.
+func (c *Client) withHint(req map[string]any) map[string]any {
+	if c.host != "" {
+		req["client_host"] = c.host
+	}
+	if c.rack != "" {
+		req["client_rack"] = c.rack
+	}
+	return req
+}
+
+// ChunkSize returns the chunk size files are split by.
+func (c *Client) ChunkSize() int64 {
+	return c.chunkSize
+}
+
+// withRetry runs fn until it succeeds, fails with a non-retryable error, the
+// policy's attempts are used up or ctx ends.
+func (c *Client) withRetry(ctx context.Context, fn func() error) error {
+	wait := c.retry.Backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || !retryable(err) || attempt >= c.retry.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait = min(2*wait, c.retry.MaxBackoff)
+	}
+}
+
+// checkChunkSize fails if the master splits name into chunks of a size
+// other than ours, which would map offsets to the wrong chunks.
+func (c *Client) checkChunkSize(name string, size int64) error {
+	if size != 0 && size != c.chunkSize {
+		return fmt.Errorf("gfs: %s: master uses %d-byte chunks, client is configured for %d", name, size, c.chunkSize)
+	}
+	return nil
+}
+
+// postJSON posts payload to the master endpoint path and decodes the reply
+// into out, which may be nil. Transport errors and 5xx replies are retried,
+// so path must be safe to repeat.
+func (c *Client) postJSON(ctx context.Context, path string, payload, out any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return c.withRetry(ctx, func() error { return c.post(ctx, path, b, out) })
+}
+
+// postJSONOnce is postJSON for requests that change the master's state and
+// can't be repeated blindly: a reply lost after the master acted would
+// apply them twice.
+func (c *Client) postJSONOnce(ctx context.Context, path string, payload, out any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return c.post(ctx, path, b, out)
+}
+
+// post sends one request with body b to path and decodes the reply into out.
+func (c *Client) post(ctx context.Context, path string, b []byte, out any) error {
+	url := c.master + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(path, url, resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("gfs: %s: invalid response: %w", path, err)
+	}
+	return nil
+}
+
+// statusError builds a *StatusError from a non-200 response.
+func statusError(op, url string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return &StatusError{
+		Op:         op,
+		URL:        url,
+		StatusCode: resp.StatusCode,
+		Message:    string(bytes.TrimSpace(body)),
+	}
+}
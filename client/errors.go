@@ -0,0 +1,72 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrNotFound is matched by errors for files or chunks the cluster doesn't know.
+	ErrNotFound = errors.New("gfs: not found")
+	// ErrExist is matched by errors for names that are already taken.
+	ErrExist = errors.New("gfs: already exists")
+	// ErrNoReplica means none of a chunk's replicas could serve a request.
+	ErrNoReplica = errors.New("gfs: no replica available")
+	// ErrNoPrimary means the master could not grant a write lease.
+	ErrNoPrimary = errors.New("gfs: no primary assigned")
+)
+
+// StatusError is a non-200 reply from the master or a chunkserver.
+type StatusError struct {
+	Op         string // endpoint, e.g. "/allocate"
+	URL        string
+	StatusCode int
+	Message    string // response body
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("gfs: %s: %s", e.Op, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("gfs: %s: %s: %s", e.Op, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Is makes a 404 match ErrNotFound and a 409 match ErrExist.
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrExist:
+		return e.StatusCode == http.StatusConflict
+	}
+	return false
+}
+
+// Temporary reports whether the request may succeed if retried.
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode >= 500
+}
+
+// ChunkError wraps a failure to read or write one chunk.
+type ChunkError struct {
+	ChunkID string
+	Op      string // "read" or "write"
+	Err     error
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("gfs: %s chunk %s: %v", e.Op, e.ChunkID, e.Err)
+}
+
+func (e *ChunkError) Unwrap() error { return e.Err }
+
+// retryable reports whether err is worth another attempt: transport errors
+// and 5xx replies are, 4xx replies are not.
+func retryable(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Temporary()
+	}
+	return true
+}
@@ -0,0 +1,367 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"sync"
+)
+
+// File is a handle on a GFS file returned by Open (read-only), or Create or
+// OpenAppend (write-only). It holds at most one chunk in memory, so io.Copy to or from
+// it runs in bounded memory whatever the file size.
+//
+// Offsets map to chunks by the client's ChunkSize: byte off lives in chunk
+// off/ChunkSize at position off%ChunkSize. A read handle sees the file's
+// committed length as of Open and returns io.EOF there.
+type File struct {
+	c    *Client
+	ctx  context.Context
+	name string
+	mode int // fileRead or fileWrite
+
+	mu     sync.Mutex
+	off    int64 // Read/Write position
+	size   int64 // file length for reads, -1 for writes
+	closed bool
+
+	// reads: the chunk currently held and its index (-1 for none);
+	// writes: the bytes not yet written as a chunk
+	cur int64
+	buf []byte
+
+	// writes: the index the buffer goes to, and how many chunks the file
+	// had when opened. Chunks below count are rewritten rather than
+	// allocated, which is how appends fill a partial last chunk.
+	next  int64
+	count int64
+}
+
+const (
+	fileRead = iota
+	fileWrite
+)
+
+// Open opens name for reading. ctx bounds every request the handle makes.
+func (c *Client) Open(ctx context.Context, name string) (*File, error) {
+	st, err := c.Stat(ctx, name)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		return nil, err
+	}
+	if err := c.checkChunkSize(name, st.ChunkSize); err != nil {
+		return nil, err
+	}
+	return &File{c: c, ctx: ctx, name: name, mode: fileRead, size: st.Length, cur: -1}, nil
+}
+
+// Create creates the empty file name and opens it for writing. Data is
+// written one chunk at a time as the buffer fills; Close writes the rest.
+// Files can't be truncated, so Create fails with fs.ErrExist if name
+// already exists.
+func (c *Client) Create(ctx context.Context, name string) (*File, error) {
+	_, err := c.Stat(ctx, name)
+	if err == nil {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrExist}
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if _, err := c.Allocate(ctx, name, 0); err != nil {
+		return nil, err
+	}
+	return &File{c: c, ctx: ctx, name: name, mode: fileWrite, size: -1, cur: -1}, nil
+}
+
+// OpenAppend opens name for writing at its end, creating it if it doesn't
+// exist. The bytes of a partial last chunk are read back into the buffer
+// and that chunk is rewritten with them on the first flush, so the file
+// stays dense. Appends from several handles at once are not coordinated.
+func (c *Client) OpenAppend(ctx context.Context, name string) (*File, error) {
+	st, err := c.Stat(ctx, name)
+	if errors.Is(err, ErrNotFound) {
+		return &File{c: c, ctx: ctx, name: name, mode: fileWrite, size: -1, cur: -1}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkChunkSize(name, st.ChunkSize); err != nil {
+		return nil, err
+	}
+	f := &File{c: c, ctx: ctx, name: name, mode: fileWrite, size: -1, cur: -1, off: st.Length, count: st.Chunks}
+	f.next = st.Length / c.chunkSize
+	if tail := st.Length % c.chunkSize; tail > 0 {
+		id, err := c.chunkAt(ctx, name, f.next)
+		if err != nil {
+			return nil, err
+		}
+		data, err := c.ReadChunkRange(ctx, id, 0, tail)
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(data)) < tail {
+			return nil, &ChunkError{ChunkID: id, Op: "read", Err: io.ErrUnexpectedEOF}
+		}
+		f.buf = make([]byte, 0, c.chunkSize)
+		f.buf = append(f.buf, data...)
+	}
+	return f, nil
+}
+
+// Name returns the file's name.
+func (f *File) Name() string {
+	return f.name
+}
+
+func (f *File) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check(fileRead); err != nil {
+		return 0, err
+	}
+	n, err := f.readAt(p, f.off)
+	f.off += int64(n)
+	return n, err
+}
+
+// ReadAt reads len(p) bytes at off. It does not move the Read offset, and
+// fetches only the bytes asked for rather than whole chunks.
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("gfs: negative offset")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check(fileRead); err != nil {
+		return 0, err
+	}
+	n := 0
+	for n < len(p) {
+		m, err := f.readRangeAt(p[n:], off+int64(n))
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// readRangeAt is readAt for random access: unless the chunk at off is the
+// one held, it fetches just the bytes p needs from that chunk and keeps the
+// held chunk. Callers must hold f.mu.
+func (f *File) readRangeAt(p []byte, off int64) (int, error) {
+	idx, within := off/f.c.chunkSize, off%f.c.chunkSize
+	if idx == f.cur || off >= f.size || len(p) == 0 {
+		return f.readAt(p, off)
+	}
+	id, err := f.c.chunkAt(f.ctx, f.name, idx)
+	if err != nil {
+		return 0, err
+	}
+	want := min(int64(len(p)), f.c.chunkSize-within, f.size-off)
+	data, err := f.c.ReadChunkRange(f.ctx, id, within, want)
+	if err != nil {
+		return 0, err
+	}
+	if int64(len(data)) < want {
+		return copy(p, data), io.ErrUnexpectedEOF
+	}
+	return copy(p, data), nil
+}
+
+// readAt copies bytes at off from the chunk holding it, loading that chunk
+// if needed. It stops at the chunk's end or the file's. Callers must hold
+// f.mu.
+func (f *File) readAt(p []byte, off int64) (int, error) {
+	if off >= f.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	idx, within := off/f.c.chunkSize, off%f.c.chunkSize
+	if idx != f.cur {
+		id, err := f.c.chunkAt(f.ctx, f.name, idx)
+		if err != nil {
+			return 0, err
+		}
+		data, err := f.c.ReadChunk(f.ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		f.cur, f.buf = idx, data
+	}
+	if within >= int64(len(f.buf)) {
+		// the chunk holds less than the file length says
+		return 0, io.ErrUnexpectedEOF
+	}
+	avail := f.buf[within:min(int64(len(f.buf)), within+f.size-off)]
+	return copy(p, avail), nil
+}
+
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check(fileWrite); err != nil {
+		return 0, err
+	}
+	n := 0
+	for n < len(p) {
+		if f.buf == nil {
+			f.buf = make([]byte, 0, f.c.chunkSize)
+		}
+		m := min(len(p)-n, cap(f.buf)-len(f.buf))
+		f.buf = append(f.buf, p[n:n+m]...)
+		n += m
+		f.off += int64(m)
+		if int64(len(f.buf)) == f.c.chunkSize {
+			if err := f.flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// flush writes the buffered bytes as chunk f.next, allocating it unless
+// the file already has it. Callers must hold f.mu.
+func (f *File) flush() error {
+	if len(f.buf) == 0 {
+		return nil
+	}
+	var id string
+	if f.next < f.count {
+		var err error
+		if id, err = f.c.chunkAt(f.ctx, f.name, f.next); err != nil {
+			return err
+		}
+	} else {
+		alloc, err := f.c.Allocate(f.ctx, f.name, int64(len(f.buf)))
+		if err != nil {
+			return err
+		}
+		id = alloc.ChunkIDs[0]
+	}
+	if err := f.c.WriteChunk(f.ctx, id, f.buf); err != nil {
+		return err
+	}
+	f.buf = f.buf[:0]
+	f.next++
+	return nil
+}
+
+// ReadFrom writes everything from r to the file, up to Parallelism chunks
+// at once. io.Copy uses it when copying into a write handle. As with Write,
+// a short last chunk stays buffered until Close or later writes fill it.
+func (f *File) ReadFrom(r io.Reader) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check(fileWrite); err != nil {
+		return 0, err
+	}
+	// top up the partial chunk, and fill any chunks the file already has,
+	// so chunks allocated by appendFrom stay aligned
+	var n int64
+	for len(f.buf) > 0 || f.next < f.count {
+		if f.buf == nil {
+			f.buf = make([]byte, 0, f.c.chunkSize)
+		}
+		m, err := io.ReadFull(r, f.buf[len(f.buf):cap(f.buf)])
+		f.buf = f.buf[:len(f.buf)+m]
+		n += int64(m)
+		f.off += int64(m)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if err := f.flush(); err != nil {
+			return n, err
+		}
+	}
+	m, tail, err := f.c.appendFrom(f.ctx, f.name, r, true)
+	f.off += m
+	f.next += (m - int64(len(tail))) / f.c.chunkSize
+	f.buf = tail
+	return n + m, err
+}
+
+// WriteTo writes the rest of the file to w, reading up to Parallelism chunks
+// ahead. io.Copy uses it when copying from a read handle.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check(fileRead); err != nil {
+		return 0, err
+	}
+	n, err := f.c.readRange(f.ctx, f.name, f.off, f.size, w)
+	f.off += n
+	return n, err
+}
+
+// Seek sets the Read offset. A write handle only reports its position.
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, fs.ErrClosed
+	}
+	if f.mode == fileWrite {
+		if offset != 0 || whence != io.SeekCurrent {
+			return 0, errors.New("gfs: write handles only append")
+		}
+		return f.off, nil
+	}
+
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = f.off + offset
+	case io.SeekEnd:
+		abs = f.size + offset
+	default:
+		return 0, errors.New("gfs: invalid whence")
+	}
+	if abs < 0 {
+		return 0, errors.New("gfs: negative position")
+	}
+	f.off = abs
+	return abs, nil
+}
+
+// Close releases the handle. For a write handle it first writes any
+// buffered data.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return fs.ErrClosed
+	}
+	f.closed = true
+	var err error
+	if f.mode == fileWrite {
+		err = f.flush()
+	}
+	f.buf = nil
+	return err
+}
+
+// check fails if f is closed or wasn't opened for mode. Callers must hold f.mu.
+func (f *File) check(mode int) error {
+	if f.closed {
+		return fs.ErrClosed
+	}
+	if f.mode != mode {
+		if mode == fileRead {
+			return errors.New("gfs: file not open for reading")
+		}
+		return errors.New("gfs: file not open for writing")
+	}
+	return nil
+}
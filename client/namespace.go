@@ -0,0 +1,50 @@
+package client
+
+import "context"
+
+// DirEntry is a file or directory in a listing. Names are full paths
+// without a leading slash. Directories have no length or chunks.
+type DirEntry struct {
+	Name        string `json:"name"`
+	Dir         bool   `json:"dir,omitempty"`
+	Length      int64  `json:"length"`
+	Chunks      int64  `json:"chunks"`
+	Replication int    `json:"replication,omitempty"`
+}
+
+// List returns the entries of directory dir, sorted by name, or the file
+// itself if dir names a file. "" and "/" are the root. With recursive set it
+// returns everything under dir. It fails with ErrNotFound if nothing is
+// there.
+func (c *Client) List(ctx context.Context, dir string, recursive bool) ([]DirEntry, error) {
+	var resp struct {
+		Entries []DirEntry `json:"entries"`
+	}
+	if err := c.postJSON(ctx, "/ls", map[string]any{"path": dir, "recursive": recursive}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Entries, nil
+}
+
+// Mkdir makes directory dir. With parents set it also makes missing
+// parents and succeeds if dir already exists; otherwise it fails with
+// ErrExist or, for a missing parent, ErrNotFound. Directories also come
+// into being implicitly when a file is created under them.
+func (c *Client) Mkdir(ctx context.Context, dir string, parents bool) error {
+	return c.postJSONOnce(ctx, "/mkdir", map[string]any{"path": dir, "parents": parents}, nil)
+}
+
+// Remove deletes the file or directory name. A directory that isn't empty
+// needs recursive, and fails with ErrExist without it. The master frees
+// the chunks in the background.
+func (c *Client) Remove(ctx context.Context, name string, recursive bool) error {
+	defer c.cache.forgetFiles()
+	return c.postJSONOnce(ctx, "/delete", map[string]any{"path": name, "recursive": recursive}, nil)
+}
+
+// Rename moves the file or directory from to to. It fails with ErrNotFound
+// if from doesn't exist and ErrExist if to does.
+func (c *Client) Rename(ctx context.Context, from, to string) error {
+	defer c.cache.forgetFiles()
+	return c.postJSONOnce(ctx, "/rename", map[string]string{"from": from, "to": to}, nil)
+}
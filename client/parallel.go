@@ -0,0 +1,276 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"sync"
+)
+
+// slots is how many chunks may be in flight at once: Parallelism, further
+// capped so that in-flight chunk buffers fit in MemoryBudget.
+func (c *Client) slots() int {
+	return max(1, min(c.parallel, int(c.budget/c.chunkSize)))
+}
+
+// group runs goroutines and keeps the first error, cancelling ctx on it.
+type group struct {
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+	cancel context.CancelFunc
+}
+
+func (g *group) run(fn func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := fn(); err != nil {
+			g.once.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+func (g *group) wait() error {
+	g.wg.Wait()
+	return g.err
+}
+
+// writeParallel writes data to the allocated chunks, up to slots at once.
+func (c *Client) writeParallel(ctx context.Context, chunkIDs []string, data []byte) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	g := &group{cancel: cancel}
+	sem := make(chan struct{}, c.slots())
+
+	size := int64(len(data))
+	for i, cid := range chunkIDs {
+		start := min(int64(i)*c.chunkSize, size)
+		end := min(start+c.chunkSize, size)
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			g.wait()
+			return firstErr(g.err, ctx.Err())
+		}
+		g.run(func() error {
+			defer func() { <-sem }()
+			return c.WriteChunk(ctx, cid, data[start:end])
+		})
+	}
+	return g.wait()
+}
+
+// UploadFrom copies r into the new file name, reading one chunk at a time and
+// writing up to Parallelism chunks at once. At most MemoryBudget bytes of
+// chunk data are buffered. It returns the number of bytes written, and
+// fails with fs.ErrExist if name already exists.
+func (c *Client) UploadFrom(ctx context.Context, name string, r io.Reader) (int64, error) {
+	_, err := c.Stat(ctx, name)
+	if err == nil {
+		return 0, &fs.PathError{Op: "upload", Path: name, Err: fs.ErrExist}
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return 0, err
+	}
+	// create the file first, so an empty r leaves an empty file
+	if _, err := c.Allocate(ctx, name, 0); err != nil {
+		return 0, err
+	}
+	n, _, err := c.appendFrom(ctx, name, r, false)
+	return n, err
+}
+
+// appendFrom is UploadFrom without the check: it adds whole chunks from r
+// after name's existing ones, which must all be full. With keepTail, a short
+// last chunk is not written but returned in a buffer of chunk size capacity;
+// its bytes count toward n.
+func (c *Client) appendFrom(ctx context.Context, name string, r io.Reader, keepTail bool) (int64, []byte, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	g := &group{cancel: cancel}
+
+	// each token is a chunk buffer, allocated on first use
+	free := make(chan []byte, c.slots())
+	for range c.slots() {
+		free <- nil
+	}
+
+	var total int64
+	var tail []byte
+	for {
+		var buf []byte
+		select {
+		case buf = <-free:
+		case <-ctx.Done():
+			g.wait()
+			return total, nil, firstErr(g.err, ctx.Err())
+		}
+		if buf == nil {
+			buf = make([]byte, c.chunkSize)
+		}
+
+		n, rerr := io.ReadFull(r, buf)
+		if n == 0 {
+			free <- buf
+			if rerr == io.EOF {
+				break
+			}
+			g.wait()
+			return total, nil, firstErr(g.err, rerr)
+		}
+		if keepTail && rerr == io.ErrUnexpectedEOF {
+			total += int64(n)
+			tail = buf[:n]
+			break
+		}
+
+		// allocation stays sequential so chunk indexes follow file order
+		alloc, err := c.Allocate(ctx, name, int64(n))
+		if err != nil {
+			g.wait()
+			return total, nil, firstErr(g.err, err)
+		}
+		total += int64(n)
+		g.run(func() error {
+			defer func() { free <- buf }()
+			return c.WriteChunk(ctx, alloc.ChunkIDs[0], buf[:n])
+		})
+
+		if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+			break
+		}
+		if rerr != nil {
+			g.wait()
+			return total, nil, firstErr(g.err, rerr)
+		}
+	}
+	return total, tail, g.wait()
+}
+
+// errEnd is returned by a readOrdered next function past the last chunk.
+var errEnd = errors.New("end of file")
+
+// piece is the part of a chunk a read needs: n bytes from off, or the rest
+// of the chunk when n is 0.
+type piece struct {
+	chunkID string
+	off, n  int64
+}
+
+// readOrdered fetches pieces next(0), next(1), ... with up to slots reads in
+// flight and hands their data to emit in order. next returns errEnd when
+// there are no more.
+func (c *Client) readOrdered(ctx context.Context, next func(i int) (piece, error), emit func([]byte) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		data []byte
+		err  error
+	}
+	// a chunk is reserved in queue before its read starts, so at most
+	// cap(queue) reads wait here plus the one emit is working on
+	queue := make(chan chan result, c.slots()-1)
+	go func() {
+		defer close(queue)
+		for i := 0; ; i++ {
+			ch := make(chan result, 1)
+			select {
+			case queue <- ch:
+			case <-ctx.Done():
+				return
+			}
+			p, err := next(i)
+			if err != nil {
+				ch <- result{nil, err}
+				return
+			}
+			go func() {
+				data, err := c.ReadChunkRange(ctx, p.chunkID, p.off, p.n)
+				if err == nil && int64(len(data)) < p.n {
+					// the chunk holds less than the file length says
+					err = io.ErrUnexpectedEOF
+				}
+				ch <- result{data, err}
+			}()
+		}
+	}()
+
+	for ch := range queue {
+		var res result
+		select {
+		case res = <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if res.err == errEnd {
+			return nil
+		}
+		if res.err != nil {
+			return res.err
+		}
+		if err := emit(res.data); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// DownloadTo writes the contents of file name to w, reading up to
+// Parallelism chunks ahead. It returns the number of bytes written.
+func (c *Client) DownloadTo(ctx context.Context, name string, w io.Writer) (int64, error) {
+	st, err := c.Stat(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	if err := c.checkChunkSize(name, st.ChunkSize); err != nil {
+		return 0, err
+	}
+	return c.readRange(ctx, name, 0, st.Length, w)
+}
+
+// readRange writes bytes [off, end) of file name to w. end is the file
+// length or less, so reads stop there rather than at the end of the last
+// chunk. Only the bytes in the range are fetched.
+func (c *Client) readRange(ctx context.Context, name string, off, end int64, w io.Writer) (int64, error) {
+	if off >= end {
+		return 0, nil
+	}
+	first := off / c.chunkSize
+	last := (end - 1) / c.chunkSize
+	next := func(i int) (piece, error) {
+		idx := first + int64(i)
+		if idx > last {
+			return piece{}, errEnd
+		}
+		id, err := c.chunkAt(ctx, name, idx)
+		if err != nil {
+			return piece{}, err
+		}
+		base := idx * c.chunkSize
+		lo := max(off, base) - base
+		hi := min(end, base+c.chunkSize) - base
+		return piece{chunkID: id, off: lo, n: hi - lo}, nil
+	}
+	var total int64
+	err := c.readOrdered(ctx, next, func(data []byte) error {
+		n, err := w.Write(data)
+		total += int64(n)
+		return err
+	})
+	return total, err
+}
+
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
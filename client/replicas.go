@@ -0,0 +1,236 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// statsWeight is how much one read moves a replica's averages.
+	statsWeight = 0.2
+	// minHedgeDelay keeps hedges from firing on replicas too fast to measure.
+	minHedgeDelay = 10 * time.Millisecond
+)
+
+// replicaStats tracks how each chunkserver has served this client's reads:
+// time to first byte, error rate and reads in flight.
+type replicaStats struct {
+	mu    sync.Mutex
+	addrs map[string]*replicaStat
+}
+
+type replicaStat struct {
+	latency  time.Duration // moving average, 0 until the first success
+	errRate  float64       // moving average of failures, 0..1
+	inflight int
+}
+
+func newReplicaStats() *replicaStats {
+	return &replicaStats{addrs: make(map[string]*replicaStat)}
+}
+
+func (s *replicaStats) get(addr string) *replicaStat {
+	st, ok := s.addrs[addr]
+	if !ok {
+		st = &replicaStat{}
+		s.addrs[addr] = st
+	}
+	return st
+}
+
+// errOutrun marks a read dropped because another replica answered first.
+var errOutrun = errors.New("another replica answered first")
+
+// start notes a read to addr and returns a func that records how it went.
+// A read the caller cancelled says nothing about the replica and is only
+// taken off the in-flight count. A read that lost a hedge counts the time it
+// kept us waiting as its latency, so a stalled replica stops ranking first.
+func (s *replicaStats) start(addr string) func(latency time.Duration, err error) {
+	s.mu.Lock()
+	s.get(addr).inflight++
+	s.mu.Unlock()
+	return func(latency time.Duration, err error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		st := s.get(addr)
+		st.inflight--
+		switch {
+		case errors.Is(err, errOutrun):
+			st.observe(latency)
+		case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		case err != nil:
+			st.errRate += statsWeight * (1 - st.errRate)
+		default:
+			st.errRate -= statsWeight * st.errRate
+			st.observe(latency)
+		}
+	}
+}
+
+func (st *replicaStat) observe(latency time.Duration) {
+	if st.latency == 0 {
+		st.latency = latency
+	} else {
+		st.latency += time.Duration(statsWeight * float64(latency-st.latency))
+	}
+}
+
+// score is a replica's expected cost; lower is better. A replica never
+// read from scores 0 so it gets tried.
+func (st *replicaStat) score() float64 {
+	return float64(st.latency) * float64(1+st.inflight) * (1 + 4*st.errRate)
+}
+
+// rank orders locs for a read: replicas on host first, then the rest by
+// score. Ties keep the master's order, which already puts the reader's rack
+// first.
+func (s *replicaStats) rank(locs []string, host string) []string {
+	out := append([]string(nil), locs...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	local := func(addr string) bool {
+		h, _, err := net.SplitHostPort(addr)
+		return host != "" && err == nil && h == host
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		li, lj := local(out[i]), local(out[j])
+		if li != lj {
+			return li
+		}
+		return s.get(out[i]).score() < s.get(out[j]).score()
+	})
+	return out
+}
+
+// hedgeDelay is how long to wait for addr to start answering before asking
+// a second replica.
+func (s *replicaStats) hedgeDelay(addr string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return max(3*s.get(addr).latency, minHedgeDelay)
+}
+
+// attempt is one replica's answer to a hedged read.
+type attempt struct {
+	addr    string
+	resp    *http.Response
+	err     error
+	start   time.Time
+	ttfb    time.Duration
+	done    func(time.Duration, error)
+	cancel  context.CancelFunc
+	dropped bool // cancelled because another replica answered first
+}
+
+// finish releases a and records its outcome.
+func (a *attempt) finish(latency time.Duration, err error) {
+	if a.dropped {
+		latency, err = time.Since(a.start), errOutrun
+	}
+	if a.resp != nil {
+		a.resp.Body.Close()
+	}
+	a.cancel()
+	a.done(latency, err)
+}
+
+// readHedged reads the range from the best of locs. If that replica hasn't
+// started answering within its hedge delay, the next one is asked too; the
+// first to answer is read and the other is cancelled before its data is
+// sent. A failed replica hands over to the next at once and drops chunkID's
+// cached locations. It returns the error of the last replica tried.
+func (c *Client) readHedged(ctx context.Context, chunkID string, locs []string, off, n int64) ([]byte, error) {
+	if len(locs) == 0 {
+		c.cache.invalidate(chunkID)
+		return nil, ErrNoReplica
+	}
+	locs = c.replicas.rank(locs, c.host)
+	results := make(chan *attempt, len(locs))
+	var launched []*attempt
+	inflight := 0
+	launch := func() {
+		addr := locs[len(launched)]
+		actx, cancel := context.WithCancel(ctx)
+		a := &attempt{addr: addr, cancel: cancel, done: c.replicas.start(addr), start: time.Now()}
+		launched = append(launched, a)
+		inflight++
+		go func() {
+			a.resp, a.err = c.openReplica(actx, addr, chunkID, off, n)
+			a.ttfb = time.Since(a.start)
+			results <- a
+		}()
+	}
+	hedge := func() <-chan time.Time {
+		if c.hedge < 0 || len(launched) >= len(locs) {
+			return nil
+		}
+		d := c.hedge
+		if d == 0 {
+			d = c.replicas.hedgeDelay(locs[len(launched)-1])
+		}
+		return time.After(d)
+	}
+
+	launch()
+	timer := hedge()
+	lastErr := ErrNoReplica
+	for inflight > 0 {
+		select {
+		case a := <-results:
+			inflight--
+			if a.dropped {
+				a.finish(0, context.Canceled)
+				continue
+			}
+			err := a.err
+			if err == nil {
+				// first to answer: stop the others before they send data
+				for _, o := range launched {
+					if o != a {
+						o.dropped = true
+						o.cancel()
+					}
+				}
+				var data []byte
+				data, err = io.ReadAll(a.resp.Body)
+				a.finish(a.ttfb, err)
+				if err == nil {
+					go drain(results, inflight)
+					return data, nil
+				}
+			} else {
+				a.finish(0, err)
+			}
+			if ctx.Err() != nil {
+				go drain(results, inflight)
+				return nil, ctx.Err()
+			}
+			c.cache.invalidate(chunkID)
+			lastErr = fmt.Errorf("%w: %s: %v", ErrNoReplica, a.addr, err)
+			if len(launched) < len(locs) {
+				launch()
+				timer = hedge()
+			}
+		case <-timer:
+			timer = nil
+			if len(launched) < len(locs) && inflight == 1 {
+				launch()
+			}
+		}
+	}
+	return nil, lastErr
+}
+
+// drain waits for n abandoned attempts and releases them.
+func drain(results <-chan *attempt, n int) {
+	for range n {
+		(<-results).finish(0, context.Canceled)
+	}
+}
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gfs/client"
+)
+
+// parseFlags parses a subcommand's flags, wanting between min and max
+// positional arguments (max -1 for no limit).
+func parseFlags(fs *flag.FlagSet, args []string, min, max int) error {
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	if n := fs.NArg(); n < min || (max >= 0 && n > max) {
+		return usagef("wrong number of arguments")
+	}
+	return nil
+}
+
+// cleanPath normalizes a remote path the way the master does.
+func cleanPath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
+
+// isDir reports whether p is a directory in the cluster; false when
+// nothing is there.
+func isDir(ctx context.Context, c *client.Client, p string) (bool, error) {
+	entries, err := c.List(ctx, p, false)
+	if errors.Is(err, client.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return len(entries) != 1 || entries[0].Name != cleanPath(p) || entries[0].Dir, nil
+}
+
+// openLocal opens a local file for reading, or stdin for "-". size is -1
+// when unknown.
+func openLocal(name string) (io.ReadCloser, int64, error) {
+	if name == "-" {
+		return io.NopCloser(os.Stdin), -1, nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	if st.IsDir() {
+		f.Close()
+		return nil, 0, fmt.Errorf("%s is a directory", name)
+	}
+	return f, st.Size(), nil
+}
+
+// put uploads to a temporary name next to the target and renames it into
+// place, so a failed upload never leaves a partial file under the real
+// name. -f replaces an existing file.
+func cmdPut(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("put", flag.ContinueOnError)
+	force := fs.Bool("f", false, "replace an existing file")
+	if err := parseFlags(fs, args, 2, 2); err != nil {
+		return err
+	}
+	local, remote := fs.Arg(0), fs.Arg(1)
+
+	dir, err := isDir(ctx, c, remote)
+	if err != nil {
+		return err
+	}
+	if dir {
+		if local == "-" {
+			return fmt.Errorf("%s is a directory", remote)
+		}
+		remote = path.Join(remote, filepath.Base(local))
+	}
+	if _, err := c.Stat(ctx, remote); err == nil && !*force {
+		return &iofs.PathError{Op: "put", Path: remote, Err: iofs.ErrExist}
+	} else if err != nil && !errors.Is(err, client.ErrNotFound) {
+		return err
+	}
+
+	src, size, err := openLocal(local)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := tempName(remote)
+	if err := upload(ctx, c, tmp, src, size, remote); err != nil {
+		c.Remove(context.WithoutCancel(ctx), tmp, false)
+		return err
+	}
+	if *force {
+		if err := c.Remove(ctx, remote, false); err != nil && !errors.Is(err, client.ErrNotFound) {
+			c.Remove(context.WithoutCancel(ctx), tmp, false)
+			return err
+		}
+	}
+	if err := c.Rename(ctx, tmp, remote); err != nil {
+		c.Remove(context.WithoutCancel(ctx), tmp, false)
+		return err
+	}
+	return nil
+}
+
+// tempName returns a hidden name in the same directory as p.
+func tempName(p string) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	dir, base := path.Split(cleanPath(p))
+	return dir + "." + base + ".gfs-put-" + hex.EncodeToString(b)
+}
+
+func upload(ctx context.Context, c *client.Client, name string, src io.Reader, size int64, label string) error {
+	f, err := c.Create(ctx, name)
+	if err != nil {
+		return err
+	}
+	p := startProgress(label, size)
+	_, err = io.Copy(f, p.reader(src))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	p.stop(err)
+	return err
+}
+
+func cmdGet(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 2, 2); err != nil {
+		return err
+	}
+	remote, local := fs.Arg(0), fs.Arg(1)
+
+	f, err := c.Open(ctx, remote)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if local == "-" {
+		_, err := io.Copy(os.Stdout, f)
+		return err
+	}
+	if st, err := os.Stat(local); err == nil && st.IsDir() {
+		local = filepath.Join(local, path.Base(cleanPath(remote)))
+	}
+	out, err := os.Create(local)
+	if err != nil {
+		return err
+	}
+	p := startProgress(remote, size)
+	_, err = io.Copy(p.writer(out), f)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	p.stop(err)
+	if err != nil {
+		os.Remove(local)
+	}
+	return err
+}
+
+func cmdCat(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("cat", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, -1); err != nil {
+		return err
+	}
+	for _, name := range fs.Args() {
+		if _, err := c.DownloadTo(ctx, name, os.Stdout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func cmdLs(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("ls", flag.ContinueOnError)
+	long := fs.Bool("l", false, "show size, chunks and replication")
+	recursive := fs.Bool("R", false, "list subdirectories too")
+	if err := parseFlags(fs, args, 0, -1); err != nil {
+		return err
+	}
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"/"}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', tabwriter.AlignRight)
+	defer w.Flush()
+	var failed error
+	for _, p := range paths {
+		entries, err := c.List(ctx, p, *recursive)
+		if err != nil {
+			w.Flush()
+			fmt.Fprintf(os.Stderr, "gfs ls: %s: %s\n", p, strings.TrimPrefix(err.Error(), "gfs: "))
+			failed = keepFirst(failed, err)
+			continue
+		}
+		for _, e := range entries {
+			name := "/" + e.Name
+			if e.Dir {
+				name += "/"
+			}
+			switch {
+			case !*long:
+				fmt.Fprintln(w, name)
+			case e.Dir:
+				fmt.Fprintf(w, "d\t-\t-\t-\t  %s\n", name)
+			default:
+				fmt.Fprintf(w, "-\t%d\t%d\t%d\t  %s\n", e.Replication, e.Chunks, e.Length, name)
+			}
+		}
+	}
+	return reported(failed)
+}
+
+func cmdStat(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("stat", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, -1); err != nil {
+		return err
+	}
+	var failed error
+	for i, p := range fs.Args() {
+		if i > 0 {
+			fmt.Println()
+		}
+		st, err := c.Stat(ctx, p)
+		if errors.Is(err, client.ErrNotFound) {
+			var dir bool
+			if dir, err = isDir(ctx, c, p); err == nil && dir {
+				fmt.Printf("Path:        /%s\nType:        directory\n", cleanPath(p))
+				continue
+			}
+			if err == nil {
+				err = &iofs.PathError{Op: "stat", Path: p, Err: iofs.ErrNotExist}
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gfs stat: %s\n", strings.TrimPrefix(err.Error(), "gfs: "))
+			failed = keepFirst(failed, err)
+			continue
+		}
+		fmt.Printf("Path:        /%s\nType:        file\nSize:        %d\nChunks:      %d\nChunk size:  %d\nReplication: %d\n",
+			st.Name, st.Length, st.Chunks, st.ChunkSize, st.Replication)
+	}
+	return reported(failed)
+}
+
+func cmdRm(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("rm", flag.ContinueOnError)
+	recursive := fs.Bool("r", false, "delete directories and their contents")
+	force := fs.Bool("f", false, "ignore paths that don't exist")
+	if err := parseFlags(fs, args, 1, -1); err != nil {
+		return err
+	}
+	var failed error
+	for _, p := range fs.Args() {
+		err := c.Remove(ctx, p, *recursive)
+		if err == nil || (*force && errors.Is(err, client.ErrNotFound)) {
+			continue
+		}
+		if errors.Is(err, client.ErrExist) && !*recursive {
+			err = &notEmptyError{path: p}
+		}
+		fmt.Fprintf(os.Stderr, "gfs rm: %s\n", strings.TrimPrefix(err.Error(), "gfs: "))
+		failed = keepFirst(failed, err)
+	}
+	return reported(failed)
+}
+
+// mv moves into to when it is an existing directory, like mv(1).
+func cmdMv(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("mv", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 2, 2); err != nil {
+		return err
+	}
+	from, to := fs.Arg(0), fs.Arg(1)
+	dir, err := isDir(ctx, c, to)
+	if err != nil {
+		return err
+	}
+	if dir {
+		to = path.Join(to, path.Base(cleanPath(from)))
+	}
+	return c.Rename(ctx, from, to)
+}
+
+func cmdMkdir(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("mkdir", flag.ContinueOnError)
+	parents := fs.Bool("p", false, "make parent directories, no error if the directory exists")
+	if err := parseFlags(fs, args, 1, -1); err != nil {
+		return err
+	}
+	var failed error
+	for _, p := range fs.Args() {
+		if err := c.Mkdir(ctx, p, *parents); err != nil {
+			fmt.Fprintf(os.Stderr, "gfs mkdir: %s\n", strings.TrimPrefix(err.Error(), "gfs: "))
+			failed = keepFirst(failed, err)
+		}
+	}
+	return reported(failed)
+}
+
+func cmdAppend(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("append", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 2, 2); err != nil {
+		return err
+	}
+	local, remote := fs.Arg(0), fs.Arg(1)
+	src, size, err := openLocal(local)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	f, err := c.OpenAppend(ctx, remote)
+	if err != nil {
+		return err
+	}
+	p := startProgress(remote, size)
+	_, err = io.Copy(f, p.reader(src))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	p.stop(err)
+	return err
+}
+
+// du prints the bytes stored under each path and what they take on disk
+// with replication. Without -s each entry of a directory gets a line.
+func cmdDu(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("du", flag.ContinueOnError)
+	summary := fs.Bool("s", false, "one total per path")
+	human := fs.Bool("h", false, "sizes in K, M, G")
+	if err := parseFlags(fs, args, 0, -1); err != nil {
+		return err
+	}
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"/"}
+	}
+	size := func(n int64) string {
+		if *human {
+			return formatSize(n)
+		}
+		return fmt.Sprint(n)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	defer w.Flush()
+	var failed error
+	for _, p := range paths {
+		entries, err := c.List(ctx, p, true)
+		if err != nil {
+			w.Flush()
+			fmt.Fprintf(os.Stderr, "gfs du: %s: %s\n", p, strings.TrimPrefix(err.Error(), "gfs: "))
+			failed = keepFirst(failed, err)
+			continue
+		}
+		// sum each file into the entry of p it falls under
+		root := cleanPath(p)
+		prefix := root + "/"
+		if root == "" {
+			prefix = ""
+		}
+		type usage struct{ length, disk int64 }
+		totals := make(map[string]*usage)
+		var total usage
+		for _, e := range entries {
+			key := e.Name
+			if rest, ok := strings.CutPrefix(e.Name, prefix); ok && e.Name != root {
+				first, _, _ := strings.Cut(rest, "/")
+				key = prefix + first
+			}
+			u := totals[key]
+			if u == nil {
+				u = &usage{}
+				totals[key] = u
+			}
+			if !e.Dir {
+				u.length += e.Length
+				u.disk += e.Length * int64(e.Replication)
+				total.length += e.Length
+				total.disk += e.Length * int64(e.Replication)
+			}
+		}
+		if !*summary {
+			keys := make([]string, 0, len(totals))
+			for k := range totals {
+				if k != root {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Fprintf(w, "%s\t%s\t/%s\n", size(totals[k].length), size(totals[k].disk), k)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t/%s\n", size(total.length), size(total.disk), root)
+	}
+	return reported(failed)
+}
+
+// formatSize renders n bytes like du -h.
+func formatSize(n int64) string {
+	const units = "KMGTPE"
+	if n < 1024 {
+		return fmt.Sprint(n)
+	}
+	v := float64(n)
+	i := -1
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	if v < 10 {
+		return fmt.Sprintf("%.1f%c", v, units[i])
+	}
+	return fmt.Sprintf("%.0f%c", v, units[i])
+}
+
+// notEmptyError is rm without -r on a directory with entries. It exits
+// like any other name conflict.
+type notEmptyError struct{ path string }
+
+func (e *notEmptyError) Error() string        { return e.path + ": directory not empty, use -r" }
+func (e *notEmptyError) Is(target error) bool { return target == iofs.ErrExist }
+
+func keepFirst(first, err error) error {
+	if first != nil {
+		return first
+	}
+	return err
+}
+
+// reportedError is a failure whose message was already printed; run only
+// turns it into an exit status.
+type reportedError struct{ err error }
+
+func (e *reportedError) Error() string { return e.err.Error() }
+func (e *reportedError) Unwrap() error { return e.err }
+
+func reported(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &reportedError{err}
+}
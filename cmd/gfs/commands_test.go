@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gfs/client"
+)
+
+// fakeMaster serves the namespace endpoints put uses for files without
+// chunks, which is all an empty upload needs.
+type fakeMaster struct {
+	mu    sync.Mutex
+	files map[string]bool
+}
+
+func startFakeMaster(t *testing.T) (*fakeMaster, string) {
+	t.Helper()
+	m := &fakeMaster{files: make(map[string]bool)}
+	srv := httptest.NewServer(http.HandlerFunc(m.serve))
+	t.Cleanup(srv.Close)
+	return m, srv.URL
+}
+
+func (m *fakeMaster) serve(w http.ResponseWriter, r *http.Request) {
+	var req map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	name := func(key string) string {
+		s, _ := req[key].(string)
+		return strings.Trim(s, "/")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var resp any
+	switch r.URL.Path {
+	case "/ls":
+		p := name("path")
+		if !m.files[p] {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		resp = map[string]any{"entries": []client.DirEntry{{Name: p}}}
+	case "/stat":
+		f := name("file")
+		if !m.files[f] {
+			http.Error(w, "file not found", http.StatusNotFound)
+			return
+		}
+		resp = client.FileStat{Name: f, ChunkSize: client.DefaultChunkSize}
+	case "/allocate":
+		if size, _ := req["size_bytes"].(float64); size != 0 {
+			http.Error(w, "only empty files are supported", http.StatusInternalServerError)
+			return
+		}
+		m.files[name("file")] = true
+		resp = client.Allocation{ChunkIDs: []string{}, Locations: [][]string{}, ChunkSize: client.DefaultChunkSize}
+	case "/rename":
+		from, to := name("from"), name("to")
+		if !m.files[from] {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		delete(m.files, from)
+		m.files[to] = true
+	case "/delete":
+		delete(m.files, name("path"))
+	default:
+		http.Error(w, "unexpected "+r.URL.Path, http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (m *fakeMaster) names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	for f := range m.files {
+		out = append(out, f)
+	}
+	return out
+}
+
+func TestPutEmpty(t *testing.T) {
+	local := filepath.Join(t.TempDir(), "empty")
+	if err := os.WriteFile(local, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		src   string
+		stdin string
+	}{
+		{"file", local, ""},
+		{"stdin", "-", local},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, url := startFakeMaster(t)
+			if tt.stdin != "" {
+				f, err := os.Open(tt.stdin)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer f.Close()
+				old := os.Stdin
+				os.Stdin = f
+				defer func() { os.Stdin = old }()
+			}
+
+			if code := run([]string{"-master", url, "-q", "put", tt.src, "/empty"}); code != exitOK {
+				t.Fatalf("put exited %d, want %d", code, exitOK)
+			}
+			if got := m.names(); len(got) != 1 || got[0] != "empty" {
+				t.Errorf("master has %q after put, want just \"empty\"", got)
+			}
+		})
+	}
+}
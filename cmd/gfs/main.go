@@ -0,0 +1,159 @@
+// Command gfs reads and writes files in a GFS cluster.
+//
+//	gfs [-master url] [-chunk-size n] [-q] <command> [args]
+//
+// Exit status is 0 on success, 1 on failure, 2 for bad usage, 3 when a file
+// or directory doesn't exist and 4 when a name is already taken.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"gfs/client"
+)
+
+const (
+	exitOK       = 0
+	exitFailure  = 1
+	exitUsage    = 2
+	exitNotFound = 3
+	exitExist    = 4
+)
+
+// command is a subcommand. run gets the arguments after its name and
+// returns an error; usageError means the arguments were wrong.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(ctx context.Context, c *client.Client, args []string) error
+}
+
+var commands = []command{
+	{"put", "[-f] <local|-> <path>", "upload a file, or stdin", cmdPut},
+	{"get", "<path> <local|->", "download a file, or write it to stdout", cmdGet},
+	{"cat", "<path>...", "write files to stdout", cmdCat},
+	{"ls", "[-l] [-R] [path...]", "list directories", cmdLs},
+	{"stat", "<path>...", "show a file's size, chunks and replication", cmdStat},
+	{"rm", "[-r] [-f] <path>...", "delete files or directories", cmdRm},
+	{"mv", "<from> <to>", "rename a file or directory", cmdMv},
+	{"mkdir", "[-p] <dir>...", "make directories", cmdMkdir},
+	{"append", "<local|-> <path>", "append a file, or stdin, to a file", cmdAppend},
+	{"du", "[-s] [-h] [path...]", "show space used under paths", cmdDu},
+}
+
+// usageError is returned by commands for bad arguments.
+type usageError struct{ msg string }
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// quiet turns off progress output; set by -q.
+var quiet bool
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("gfs", flag.ContinueOnError)
+	master := flags.String("master", envOr("GFS_MASTER", "http://localhost:8080"), "master address (env GFS_MASTER)")
+	chunkSize := flags.Int64("chunk-size", envInt("GFS_CHUNK_SIZE", client.DefaultChunkSize), "chunk size in bytes, must match the master's (env GFS_CHUNK_SIZE)")
+	flags.BoolVar(&quiet, "q", false, "no progress output")
+	flags.Usage = func() { usage(flags) }
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		usage(flags)
+		return exitUsage
+	}
+
+	name := flags.Arg(0)
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "gfs: unknown command %q\n", name)
+		usage(flags)
+		return exitUsage
+	}
+
+	c, err := client.New(client.Config{MasterURL: *master, ChunkSize: *chunkSize})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gfs: %v\n", err)
+		return exitUsage
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err = cmd.run(ctx, c, flags.Args()[1:])
+	if err == nil {
+		return exitOK
+	}
+	var ue *usageError
+	if errors.As(err, &ue) {
+		fmt.Fprintf(os.Stderr, "gfs %s: %s\nusage: gfs %s %s\n", cmd.name, ue.msg, cmd.name, cmd.args)
+		return exitUsage
+	}
+	var re *reportedError
+	if !errors.As(err, &re) {
+		fmt.Fprintf(os.Stderr, "gfs %s: %s\n", cmd.name, strings.TrimPrefix(err.Error(), "gfs: "))
+	}
+	return exitCode(err)
+}
+
+// exitCode maps err to the exit status scripts can test for.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, client.ErrNotFound) || errors.Is(err, fs.ErrNotExist):
+		return exitNotFound
+	case errors.Is(err, client.ErrExist) || errors.Is(err, fs.ErrExist):
+		return exitExist
+	}
+	return exitFailure
+}
+
+func usage(flags *flag.FlagSet) {
+	out := flags.Output()
+	fmt.Fprintf(out, "usage: gfs [flags] <command> [args]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %-7s %-26s %s\n", c.name, c.args, c.summary)
+	}
+	fmt.Fprintf(out, "\nflags:\n")
+	flags.PrintDefaults()
+	fmt.Fprintf(out, "\nexit status: 0 ok, 1 failure, 2 usage, 3 not found, 4 already exists\n")
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func envInt(key string, def int64) int64 {
+	if n, err := strconv.ParseInt(os.Getenv(key), 10, 64); err == nil {
+		return n
+	}
+	return def
+}
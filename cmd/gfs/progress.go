@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// progress redraws one status line on stderr while a transfer runs. It is
+// nil, and every method a no-op, under -q or when stderr isn't a terminal.
+type progress struct {
+	name  string
+	total int64 // -1 when unknown
+	n     atomic.Int64
+	start time.Time
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func startProgress(name string, total int64) *progress {
+	if quiet || !isTerminal(os.Stderr) {
+		return nil
+	}
+	p := &progress{name: name, total: total, start: time.Now(), done: make(chan struct{})}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		t := time.NewTicker(200 * time.Millisecond)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				p.draw()
+			case <-p.done:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+func isTerminal(f *os.File) bool {
+	st, err := f.Stat()
+	return err == nil && st.Mode()&os.ModeCharDevice != 0
+}
+
+func (p *progress) draw() {
+	n := p.n.Load()
+	line := fmt.Sprintf("%s  %s", p.name, formatSize(n))
+	if p.total > 0 {
+		line += fmt.Sprintf(" / %s  %3d%%", formatSize(p.total), n*100/p.total)
+	}
+	if secs := time.Since(p.start).Seconds(); secs > 0 {
+		line += fmt.Sprintf("  %s/s", formatSize(int64(float64(n)/secs)))
+	}
+	fmt.Fprintf(os.Stderr, "\r%s\x1b[K", line)
+}
+
+// stop draws the final state and ends the line; after a failure the line
+// is cleared instead so the error message stands alone.
+func (p *progress) stop(err error) {
+	if p == nil {
+		return
+	}
+	close(p.done)
+	p.wg.Wait()
+	if err != nil {
+		fmt.Fprint(os.Stderr, "\r\x1b[K")
+		return
+	}
+	p.draw()
+	fmt.Fprintln(os.Stderr)
+}
+
+// reader counts the bytes read through r.
+func (p *progress) reader(r io.Reader) io.Reader {
+	if p == nil {
+		return r
+	}
+	return &countingReader{r: r, p: p}
+}
+
+// writer counts the bytes written through w.
+func (p *progress) writer(w io.Writer) io.Writer {
+	if p == nil {
+		return w
+	}
+	return &countingWriter{w: w, p: p}
+}
+
+type countingReader struct {
+	r io.Reader
+	p *progress
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.p.n.Add(int64(n))
+	return n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	p *progress
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.p.n.Add(int64(n))
+	return n, err
+}
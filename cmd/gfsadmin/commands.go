@@ -0,0 +1,642 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// The types below mirror the master's JSON; only the fields gfsadmin
+// prints are listed.
+
+type clusterStatus struct {
+	Nodes struct {
+		Total       int `json:"total"`
+		Alive       int `json:"alive"`
+		Dead        int `json:"dead"`
+		Draining    int `json:"draining"`
+		Maintenance int `json:"maintenance"`
+	} `json:"nodes"`
+	Capacity struct {
+		Total uint64 `json:"total_bytes"`
+		Used  uint64 `json:"used_bytes"`
+		Free  uint64 `json:"free_bytes"`
+	} `json:"capacity"`
+	Files           int    `json:"files"`
+	Dirs            int    `json:"dirs"`
+	Chunks          int    `json:"chunks"`
+	Bytes           int64  `json:"bytes"`
+	UnderReplicated int    `json:"under_replicated"`
+	Lost            int    `json:"lost"`
+	Leases          int    `json:"leases"`
+	RepairPending   int    `json:"repair_pending"`
+	RepairRunning   int    `json:"repair_running"`
+	RepairFailed    int    `json:"repair_failed"`
+	Rebalance       string `json:"rebalance"`
+}
+
+type nodeInfo struct {
+	Rack             string `json:"rack"`
+	Alive            bool   `json:"alive"`
+	Draining         bool   `json:"draining"`
+	Drained          bool   `json:"drained"`
+	MaintenanceUntil int64  `json:"maintenance_until"`
+	LastSeenUnix     int64  `json:"last_seen_unix"`
+	Conflict         string `json:"conflict"`
+	TotalBytes       uint64 `json:"total_bytes"`
+	FreeBytes        uint64 `json:"free_bytes"`
+	UsedBytes        uint64 `json:"used_bytes"`
+	ChunkCount       int    `json:"chunk_count"`
+	InflightReads    int64  `json:"inflight_reads"`
+	InflightWrites   int64  `json:"inflight_writes"`
+}
+
+type chunkHealth struct {
+	ChunkID  string   `json:"chunk_id"`
+	File     string   `json:"file"`
+	Index    int      `json:"index"`
+	Live     int      `json:"live"`
+	Want     int      `json:"want"`
+	Replicas []string `json:"replicas"`
+	Queued   bool     `json:"queued"`
+}
+
+type leaseInfo struct {
+	ChunkID     string `json:"chunk_id"`
+	File        string `json:"file"`
+	Index       int    `json:"index"`
+	Primary     string `json:"primary"`
+	ExpiresUnix int64  `json:"expires_unix"`
+	Version     uint64 `json:"version"`
+}
+
+type repairTask struct {
+	ChunkID   string    `json:"chunk_id"`
+	Live      int       `json:"live"`
+	Want      int       `json:"want"`
+	Attempts  int       `json:"attempts"`
+	NotBefore time.Time `json:"not_before"`
+	Enqueued  time.Time `json:"enqueued"`
+	LastError string    `json:"last_error"`
+	Source    string    `json:"source"`
+	Target    string    `json:"target"`
+}
+
+type repairQueueStatus struct {
+	Pending      int            `json:"pending"`
+	Running      int            `json:"running"`
+	Completed    int            `json:"completed"`
+	Failed       int            `json:"failed"`
+	LastDoneUnix int64          `json:"last_done_unix"`
+	PerNode      map[string]int `json:"per_node"`
+	RunningTasks []repairTask   `json:"running_tasks"`
+	NextTasks    []repairTask   `json:"next_tasks"`
+}
+
+type drainStatus struct {
+	Node     string   `json:"node"`
+	Draining bool     `json:"draining"`
+	Alive    bool     `json:"alive"`
+	Chunks   int      `json:"chunks"`
+	Pending  int      `json:"pending"`
+	Unique   []string `json:"unique"`
+	Done     bool     `json:"done"`
+}
+
+type rebalanceStatus struct {
+	State        string             `json:"state"`
+	Threshold    float64            `json:"threshold"`
+	Bandwidth    int64              `json:"bandwidth"`
+	StartedUnix  int64              `json:"started_unix"`
+	FinishedUnix int64              `json:"finished_unix"`
+	Moves        int                `json:"moves"`
+	Failures     int                `json:"failures"`
+	BytesMoved   int64              `json:"bytes_moved"`
+	LastError    string             `json:"last_error"`
+	AverageUtil  float64            `json:"average_utilization"`
+	NodeUtil     map[string]float64 `json:"node_utilization"`
+	Current      *struct {
+		ChunkID string `json:"chunk_id"`
+		From    string `json:"from"`
+		To      string `json:"to"`
+		Bytes   int64  `json:"bytes"`
+	} `json:"current"`
+}
+
+type fsckProblem struct {
+	Kind    string `json:"kind"`
+	File    string `json:"file"`
+	ChunkID string `json:"chunk_id"`
+	Node    string `json:"node"`
+	Detail  string `json:"detail"`
+}
+
+type fsckReport struct {
+	Path       string         `json:"path"`
+	Files      int            `json:"files"`
+	Chunks     int            `json:"chunks"`
+	Nodes      int            `json:"nodes"`
+	Healthy    bool           `json:"healthy"`
+	Counts     map[string]int `json:"counts"`
+	Problems   []fsckProblem  `json:"problems"`
+	Queued     int            `json:"queued"`
+	Trimmed    int            `json:"trimmed"`
+	Reconciled []string       `json:"reconciled"`
+	Seconds    float64        `json:"seconds"`
+}
+
+func cmdStatus(ctx context.Context, m *master, args []string) error {
+	if len(args) != 0 {
+		return usagef("no arguments expected")
+	}
+	var st clusterStatus
+	if err := m.get(ctx, "/status", &st); err != nil || jsonOut {
+		return err
+	}
+	n, c := st.Nodes, st.Capacity
+	w := newTable()
+	fmt.Fprintf(w, "nodes:\t%d alive, %d dead, %d draining, %d in maintenance\n", n.Alive, n.Dead, n.Draining, n.Maintenance)
+	fmt.Fprintf(w, "capacity:\t%s used, %s free, %s total (%s)\n",
+		formatSize(int64(c.Used)), formatSize(int64(c.Free)), formatSize(int64(c.Total)), percent(c.Used, c.Total))
+	fmt.Fprintf(w, "namespace:\t%d files, %d directories, %s\n", st.Files, st.Dirs, formatSize(st.Bytes))
+	fmt.Fprintf(w, "chunks:\t%d, %d under-replicated, %d lost\n", st.Chunks, st.UnderReplicated, st.Lost)
+	fmt.Fprintf(w, "leases:\t%d\n", st.Leases)
+	fmt.Fprintf(w, "repairs:\t%d pending, %d running, %d failed\n", st.RepairPending, st.RepairRunning, st.RepairFailed)
+	fmt.Fprintf(w, "rebalance:\t%s\n", st.Rebalance)
+	return w.Flush()
+}
+
+func cmdNodes(ctx context.Context, m *master, args []string) error {
+	if len(args) != 0 {
+		return usagef("no arguments expected")
+	}
+	var nodes map[string]nodeInfo
+	if err := m.get(ctx, "/list", &nodes); err != nil || jsonOut {
+		return err
+	}
+	addrs := make([]string, 0, len(nodes))
+	for a := range nodes {
+		addrs = append(addrs, a)
+	}
+	sort.Strings(addrs)
+
+	w := newTable()
+	fmt.Fprintln(w, "NODE\tRACK\tSTATE\tCHUNKS\tUSED\tFREE\tTOTAL\tUSE%\tREADS\tWRITES\tLAST SEEN")
+	for _, a := range addrs {
+		n := nodes[a]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%d\t%d\t%s\n",
+			a, orDash(n.Rack), nodeState(n), n.ChunkCount,
+			formatSize(int64(n.UsedBytes)), formatSize(int64(n.FreeBytes)), formatSize(int64(n.TotalBytes)),
+			percent(n.UsedBytes, n.TotalBytes), n.InflightReads, n.InflightWrites, ago(n.LastSeenUnix))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, a := range addrs {
+		if c := nodes[a].Conflict; c != "" {
+			fmt.Fprintf(os.Stderr, "warning: %s: %s\n", a, c)
+		}
+	}
+	return nil
+}
+
+// nodeState is a node's liveness followed by whatever else applies.
+func nodeState(n nodeInfo) string {
+	s := []string{"dead"}
+	if n.Alive {
+		s[0] = "alive"
+	}
+	switch {
+	case n.Drained:
+		s = append(s, "drained")
+	case n.Draining:
+		s = append(s, "draining")
+	}
+	if n.MaintenanceUntil > time.Now().Unix() {
+		s = append(s, "maintenance "+until(n.MaintenanceUntil))
+	}
+	return strings.Join(s, ",")
+}
+
+func cmdUnderReplicated(ctx context.Context, m *master, args []string) error {
+	flags := flag.NewFlagSet("under-replicated", flag.ContinueOnError)
+	max := flags.Int("n", 100, "show at most `max` chunks, 0 for all")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usagef("no arguments expected")
+	}
+	var chunks []chunkHealth
+	if err := m.get(ctx, "/under_replicated", &chunks); err != nil || jsonOut {
+		return err
+	}
+	if len(chunks) == 0 {
+		fmt.Println("every chunk is fully replicated")
+		return nil
+	}
+	shown := chunks
+	if *max > 0 && len(shown) > *max {
+		shown = shown[:*max]
+	}
+	w := newTable()
+	fmt.Fprintln(w, "CHUNK\tFILE\tINDEX\tLIVE\tWANT\tQUEUED\tREPLICAS")
+	for _, c := range shown {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\t%s\n",
+			c.ChunkID, c.File, c.Index, c.Live, c.Want, yesNo(c.Queued), orDash(strings.Join(c.Replicas, ",")))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if len(shown) < len(chunks) {
+		fmt.Printf("... and %d more\n", len(chunks)-len(shown))
+	}
+	return nil
+}
+
+func cmdLeases(ctx context.Context, m *master, args []string) error {
+	flags := flag.NewFlagSet("leases", flag.ContinueOnError)
+	node := flags.String("node", "", "only leases whose primary is `addr`")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usagef("no arguments expected")
+	}
+	path := "/leases"
+	if *node != "" {
+		path += "?node=" + url.QueryEscape(*node)
+	}
+	var leases []leaseInfo
+	if err := m.get(ctx, path, &leases); err != nil || jsonOut {
+		return err
+	}
+	if len(leases) == 0 {
+		fmt.Println("no leases outstanding")
+		return nil
+	}
+	w := newTable()
+	fmt.Fprintln(w, "CHUNK\tFILE\tINDEX\tPRIMARY\tVERSION\tEXPIRES")
+	for _, l := range leases {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%d\t%s\n", l.ChunkID, l.File, l.Index, l.Primary, l.Version, until(l.ExpiresUnix))
+	}
+	return w.Flush()
+}
+
+func cmdRepairs(ctx context.Context, m *master, args []string) error {
+	if len(args) != 0 {
+		return usagef("no arguments expected")
+	}
+	var st repairQueueStatus
+	if err := m.get(ctx, "/repair_queue", &st); err != nil || jsonOut {
+		return err
+	}
+	fmt.Printf("%d pending, %d running, %d completed, %d failed", st.Pending, st.Running, st.Completed, st.Failed)
+	if st.LastDoneUnix > 0 {
+		fmt.Printf(", last finished %s", ago(st.LastDoneUnix))
+	}
+	fmt.Println()
+
+	if len(st.RunningTasks) > 0 {
+		fmt.Println("\nrunning:")
+		w := newTable()
+		fmt.Fprintln(w, "CHUNK\tLIVE\tWANT\tSOURCE\tTARGET\tATTEMPTS\tQUEUED")
+		for _, t := range st.RunningTasks {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%d\t%s\n",
+				t.ChunkID, t.Live, t.Want, orDash(t.Source), orDash(t.Target), t.Attempts, ago(t.Enqueued.Unix()))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(st.NextTasks) > 0 {
+		fmt.Println("\nnext:")
+		w := newTable()
+		fmt.Fprintln(w, "CHUNK\tLIVE\tWANT\tATTEMPTS\tNEXT TRY\tLAST ERROR")
+		for _, t := range st.NextTasks {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%s\n",
+				t.ChunkID, t.Live, t.Want, t.Attempts, until(t.NotBefore.Unix()), orDash(t.LastError))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(st.PerNode) > 0 {
+		fmt.Println("\ncopies in progress per node:")
+		w := newTable()
+		for _, n := range sortedKeys(st.PerNode) {
+			fmt.Fprintf(w, "  %s\t%d\n", n, st.PerNode[n])
+		}
+		return w.Flush()
+	}
+	return nil
+}
+
+func cmdDecommission(ctx context.Context, m *master, args []string) error {
+	if len(args) == 0 {
+		return usagef("start, cancel or status expected")
+	}
+	switch args[0] {
+	case "start", "cancel":
+		if len(args) != 2 {
+			return usagef("%s needs one node", args[0])
+		}
+		var st drainStatus
+		req := map[string]any{"node": args[1], "cancel": args[0] == "cancel"}
+		if err := m.post(ctx, "/decommission", req, &st); err != nil || jsonOut {
+			return err
+		}
+		if args[0] == "cancel" {
+			fmt.Printf("%s: decommission cancelled\n", st.Node)
+			return nil
+		}
+		return printDrain([]drainStatus{st})
+	case "status":
+		var out []drainStatus
+		switch len(args) {
+		case 1:
+			if err := m.get(ctx, "/decommission", &out); err != nil || jsonOut {
+				return err
+			}
+			if len(out) == 0 {
+				fmt.Println("no nodes are being decommissioned")
+				return nil
+			}
+		case 2:
+			var st drainStatus
+			if err := m.get(ctx, "/decommission?node="+url.QueryEscape(args[1]), &st); err != nil || jsonOut {
+				return err
+			}
+			out = append(out, st)
+		default:
+			return usagef("status takes at most one node")
+		}
+		return printDrain(out)
+	}
+	return usagef("unknown action %q", args[0])
+}
+
+func printDrain(nodes []drainStatus) error {
+	w := newTable()
+	fmt.Fprintln(w, "NODE\tSTATE\tCHUNKS\tPENDING\tUNIQUE\tDONE")
+	for _, st := range nodes {
+		state := "dead"
+		if st.Alive {
+			state = "alive"
+		}
+		if st.Draining {
+			state += ",draining"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\n", st.Node, state, st.Chunks, st.Pending, len(st.Unique), yesNo(st.Done))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, st := range nodes {
+		if !st.Alive && len(st.Unique) > 0 {
+			fmt.Fprintf(os.Stderr, "warning: %s is down and holds the only copy of %d chunks\n", st.Node, len(st.Unique))
+		}
+	}
+	return nil
+}
+
+func cmdRebalance(ctx context.Context, m *master, args []string) error {
+	if len(args) == 0 {
+		return usagef("start, stop or status expected")
+	}
+	var st rebalanceStatus
+	switch args[0] {
+	case "start":
+		flags := flag.NewFlagSet("rebalance start", flag.ContinueOnError)
+		threshold := flags.Float64("threshold", 0, "allowed utilization spread, as a fraction (default: the master's)")
+		bandwidth := flags.Int64("bandwidth", 0, "copy rate limit in bytes per second (default: the master's)")
+		if err := parseFlags(flags, args[1:]); err != nil {
+			return err
+		}
+		if flags.NArg() != 0 {
+			return usagef("start takes no arguments")
+		}
+		req := map[string]any{}
+		if *threshold > 0 {
+			req["threshold"] = *threshold
+		}
+		if *bandwidth > 0 {
+			req["bandwidth"] = *bandwidth
+		}
+		if err := m.post(ctx, "/rebalance/start", req, &st); err != nil || jsonOut {
+			return err
+		}
+	case "stop":
+		if len(args) != 1 {
+			return usagef("stop takes no arguments")
+		}
+		if err := m.post(ctx, "/rebalance/stop", nil, &st); err != nil || jsonOut {
+			return err
+		}
+	case "status":
+		if len(args) != 1 {
+			return usagef("status takes no arguments")
+		}
+		if err := m.get(ctx, "/rebalance/status", &st); err != nil || jsonOut {
+			return err
+		}
+	default:
+		return usagef("unknown action %q", args[0])
+	}
+	return printRebalance(st)
+}
+
+func printRebalance(st rebalanceStatus) error {
+	w := newTable()
+	fmt.Fprintf(w, "state:\t%s\n", st.State)
+	if st.StartedUnix > 0 {
+		fmt.Fprintf(w, "started:\t%s\n", ago(st.StartedUnix))
+	}
+	if st.FinishedUnix > 0 {
+		fmt.Fprintf(w, "finished:\t%s\n", ago(st.FinishedUnix))
+	}
+	if st.Threshold > 0 {
+		fmt.Fprintf(w, "threshold:\t%.1f%%\n", st.Threshold*100)
+	}
+	if st.Bandwidth > 0 {
+		fmt.Fprintf(w, "bandwidth:\t%s/s\n", formatSize(st.Bandwidth))
+	}
+	fmt.Fprintf(w, "moved:\t%d chunks (%s bytes), %d failures\n", st.Moves, formatSize(st.BytesMoved), st.Failures)
+	if st.Current != nil {
+		fmt.Fprintf(w, "moving:\t%s (%s) %s -> %s\n", st.Current.ChunkID, formatSize(st.Current.Bytes), st.Current.From, st.Current.To)
+	}
+	if st.LastError != "" {
+		fmt.Fprintf(w, "last error:\t%s\n", st.LastError)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if len(st.NodeUtil) == 0 {
+		return nil
+	}
+	fmt.Println()
+	w = newTable()
+	fmt.Fprintln(w, "NODE\tUSE%\tVS AVERAGE")
+	for _, n := range sortedKeys(st.NodeUtil) {
+		u := st.NodeUtil[n]
+		d := (u - st.AverageUtil) * 100
+		if math.Abs(d) < 0.05 {
+			d = 0 // no "-0.0%"
+		}
+		fmt.Fprintf(w, "%s\t%.1f%%\t%+.1f%%\n", n, u*100, d)
+	}
+	return w.Flush()
+}
+
+func cmdCheckpoint(ctx context.Context, m *master, args []string) error {
+	if len(args) != 0 {
+		return usagef("no arguments expected")
+	}
+	var resp struct {
+		Files  int `json:"files"`
+		Chunks int `json:"chunks"`
+	}
+	if err := m.post(ctx, "/checkpoint", nil, &resp); err != nil || jsonOut {
+		return err
+	}
+	fmt.Printf("checkpoint written: %d files, %d chunks\n", resp.Files, resp.Chunks)
+	return nil
+}
+
+func cmdFsck(ctx context.Context, m *master, args []string) error {
+	flags := flag.NewFlagSet("fsck", flag.ContinueOnError)
+	repair := flags.Bool("repair", false, "queue repairs, trim extra replicas and reconcile nodes that disagree")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return usagef("at most one path expected")
+	}
+	var rep fsckReport
+	req := map[string]any{"path": flags.Arg(0), "repair": *repair}
+	if err := m.post(ctx, "/fsck", req, &rep); err != nil {
+		return err
+	}
+	if !jsonOut {
+		printFsck(os.Stdout, rep)
+	}
+	if !rep.Healthy {
+		return &problemsError{n: len(rep.Problems)}
+	}
+	return nil
+}
+
+func printFsck(out io.Writer, rep fsckReport) {
+	if len(rep.Problems) > 0 {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KIND\tFILE\tCHUNK\tNODE\tDETAIL")
+		for _, p := range rep.Problems {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Kind, orDash(p.File), orDash(p.ChunkID), orDash(p.Node), p.Detail)
+		}
+		w.Flush()
+		fmt.Fprintln(out)
+	}
+
+	path := "/" + rep.Path
+	fmt.Fprintf(out, "%s: %d files, %d chunks", path, rep.Files, rep.Chunks)
+	if rep.Nodes > 0 {
+		fmt.Fprintf(out, ", %d node reports", rep.Nodes)
+	}
+	fmt.Fprintf(out, " checked in %.1fs\n", rep.Seconds)
+	if rep.Healthy {
+		fmt.Fprintln(out, "status: healthy")
+	} else {
+		var counts []string
+		for _, k := range sortedKeys(rep.Counts) {
+			counts = append(counts, fmt.Sprintf("%d %s", rep.Counts[k], k))
+		}
+		fmt.Fprintf(out, "status: %d problems (%s)\n", len(rep.Problems), strings.Join(counts, ", "))
+	}
+	if rep.Queued > 0 || rep.Trimmed > 0 || len(rep.Reconciled) > 0 {
+		fmt.Fprintf(out, "repair: %d chunks queued for re-replication, %d trimmed, %d nodes reconciled\n",
+			rep.Queued, rep.Trimmed, len(rep.Reconciled))
+	}
+}
+
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	return nil
+}
+
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func percent(used, total uint64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(used)*100/float64(total))
+}
+
+// ago and until print a unix time relative to now, to the second.
+func ago(unix int64) string {
+	if unix <= 0 {
+		return "never"
+	}
+	return time.Since(time.Unix(unix, 0)).Truncate(time.Second).String() + " ago"
+}
+
+func until(unix int64) string {
+	d := time.Until(time.Unix(unix, 0)).Truncate(time.Second)
+	if d <= 0 {
+		return "now"
+	}
+	return "in " + d.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func formatSize(n int64) string {
+	const units = "KMGTPE"
+	if n < 1024 {
+		return fmt.Sprint(n)
+	}
+	v := float64(n)
+	i := -1
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	if v < 10 {
+		return fmt.Sprintf("%.1f%c", v, units[i])
+	}
+	return fmt.Sprintf("%.0f%c", v, units[i])
+}
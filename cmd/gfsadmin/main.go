@@ -0,0 +1,240 @@
+// Command gfsadmin inspects and operates a GFS cluster through the master's
+// admin endpoints.
+//
+//	gfsadmin [-master url] [-json] <command> [args]
+//
+// Output is a table by default; with -json it is the master's response,
+// indented. Exit status is 0 on success, 1 on failure, 2 for bad usage, 3
+// when a node or path doesn't exist, 4 when the master refuses because of
+// the cluster's state (say, a rebalance already running) and 5 when fsck
+// finds problems.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+const (
+	exitOK       = 0
+	exitFailure  = 1
+	exitUsage    = 2
+	exitNotFound = 3
+	exitConflict = 4
+	exitProblems = 5
+)
+
+// command is a subcommand. run gets the arguments after its name and
+// returns an error; usageError means the arguments were wrong.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(ctx context.Context, m *master, args []string) error
+}
+
+var commands = []command{
+	{"status", "", "summarize nodes, capacity, chunks and repairs", cmdStatus},
+	{"nodes", "", "list chunkservers with state and capacity", cmdNodes},
+	{"under-replicated", "[-n max]", "list chunks short of their replication target", cmdUnderReplicated},
+	{"leases", "[-node addr]", "list outstanding leases and their primaries", cmdLeases},
+	{"repairs", "", "show the repair queue", cmdRepairs},
+	{"decommission", "start|cancel|status [node]", "drain a chunkserver, or show draining nodes", cmdDecommission},
+	{"rebalance", "start [-threshold f] [-bandwidth n]|stop|status", "even out disk usage across chunkservers", cmdRebalance},
+	{"checkpoint", "", "write a checkpoint and truncate the operation log", cmdCheckpoint},
+	{"fsck", "[-repair] [path]", "check metadata against the chunkservers", cmdFsck},
+}
+
+// usageError is returned by commands for bad arguments.
+type usageError struct{ msg string }
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// statusError is a non-2xx answer from the master; msg is its body.
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string { return e.msg }
+
+// problemsError is fsck finding anything wrong. The report has already
+// been printed.
+type problemsError struct{ n int }
+
+func (e *problemsError) Error() string { return fmt.Sprintf("%d problems", e.n) }
+
+// jsonOut prints the master's responses instead of tables; set by -json.
+var jsonOut bool
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("gfsadmin", flag.ContinueOnError)
+	addr := flags.String("master", envOr("GFS_MASTER", "http://localhost:8080"), "master address (env GFS_MASTER)")
+	flags.BoolVar(&jsonOut, "json", false, "print the master's JSON instead of tables")
+	flags.Usage = func() { usage(flags) }
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		usage(flags)
+		return exitUsage
+	}
+
+	name := flags.Arg(0)
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "gfsadmin: unknown command %q\n", name)
+		usage(flags)
+		return exitUsage
+	}
+
+	base := strings.TrimRight(*addr, "/")
+	if !strings.Contains(base, "://") {
+		base = "http://" + base
+	}
+	if _, err := url.Parse(base); err != nil {
+		fmt.Fprintf(os.Stderr, "gfsadmin: bad master address: %v\n", err)
+		return exitUsage
+	}
+	m := &master{base: base, http: &http.Client{Timeout: 2 * time.Minute}}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := cmd.run(ctx, m, flags.Args()[1:])
+	var ue *usageError
+	var se *statusError
+	var pe *problemsError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &ue):
+		fmt.Fprintf(os.Stderr, "gfsadmin %s: %s\nusage: gfsadmin %s %s\n", cmd.name, ue.msg, cmd.name, cmd.args)
+		return exitUsage
+	case errors.As(err, &pe):
+		return exitProblems
+	case errors.As(err, &se):
+		fmt.Fprintf(os.Stderr, "gfsadmin %s: %s\n", cmd.name, se.msg)
+		switch se.code {
+		case http.StatusNotFound:
+			return exitNotFound
+		case http.StatusConflict:
+			return exitConflict
+		}
+		return exitFailure
+	}
+	fmt.Fprintf(os.Stderr, "gfsadmin %s: %v\n", cmd.name, err)
+	return exitFailure
+}
+
+func usage(flags *flag.FlagSet) {
+	out := flags.Output()
+	fmt.Fprintf(out, "usage: gfsadmin [flags] <command> [args]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %s\n    \t%s\n", strings.TrimSpace(c.name+" "+c.args), c.summary)
+	}
+	fmt.Fprintf(out, "\nflags:\n")
+	flags.PrintDefaults()
+	fmt.Fprintf(out, "\nexit status: 0 ok, 1 failure, 2 usage, 3 not found, 4 conflict, 5 fsck found problems\n")
+}
+
+// master talks to the master's HTTP API.
+type master struct {
+	base string
+	http *http.Client
+}
+
+// call sends body, if any, as JSON and decodes the response into out. Under
+// -json it also prints the response; callers check jsonOut before rendering.
+func (m *master) call(ctx context.Context, method, path string, body, out any) error {
+	var rd io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rd = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, m.base+path, rd)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := m.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		msg := strings.TrimSpace(string(data))
+		if msg == "" {
+			msg = resp.Status
+		}
+		return &statusError{code: resp.StatusCode, msg: msg}
+	}
+	if jsonOut {
+		var buf bytes.Buffer
+		if json.Indent(&buf, bytes.TrimSpace(data), "", "  ") != nil {
+			buf.Reset()
+			buf.Write(bytes.TrimSpace(data))
+		}
+		buf.WriteByte('\n')
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s: invalid response: %w", path, err)
+	}
+	return nil
+}
+
+func (m *master) get(ctx context.Context, path string, out any) error {
+	return m.call(ctx, http.MethodGet, path, nil, out)
+}
+
+func (m *master) post(ctx context.Context, path string, body, out any) error {
+	return m.call(ctx, http.MethodPost, path, body, out)
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,2322 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: gfs.proto
+
+package rpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type NodeStats struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TotalBytes     uint64                 `protobuf:"varint,1,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	FreeBytes      uint64                 `protobuf:"varint,2,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`
+	UsedBytes      uint64                 `protobuf:"varint,3,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`
+	ChunkCount     int64                  `protobuf:"varint,4,opt,name=chunk_count,json=chunkCount,proto3" json:"chunk_count,omitempty"`
+	InflightReads  int64                  `protobuf:"varint,5,opt,name=inflight_reads,json=inflightReads,proto3" json:"inflight_reads,omitempty"`
+	InflightWrites int64                  `protobuf:"varint,6,opt,name=inflight_writes,json=inflightWrites,proto3" json:"inflight_writes,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *NodeStats) Reset() {
+	*x = NodeStats{}
+	mi := &file_gfs_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeStats) ProtoMessage() {}
+
+func (x *NodeStats) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeStats.ProtoReflect.Descriptor instead.
+func (*NodeStats) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *NodeStats) GetTotalBytes() uint64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *NodeStats) GetFreeBytes() uint64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *NodeStats) GetUsedBytes() uint64 {
+	if x != nil {
+		return x.UsedBytes
+	}
+	return 0
+}
+
+func (x *NodeStats) GetChunkCount() int64 {
+	if x != nil {
+		return x.ChunkCount
+	}
+	return 0
+}
+
+func (x *NodeStats) GetInflightReads() int64 {
+	if x != nil {
+		return x.InflightReads
+	}
+	return 0
+}
+
+func (x *NodeStats) GetInflightWrites() int64 {
+	if x != nil {
+		return x.InflightWrites
+	}
+	return 0
+}
+
+type RegisterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Port          string                 `protobuf:"bytes,1,opt,name=port,proto3" json:"port,omitempty"`
+	Addr          string                 `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`                         // advertised HTTP host:port
+	Uuid          string                 `protobuf:"bytes,3,opt,name=uuid,proto3" json:"uuid,omitempty"`                         // persistent, stored in the chunkserver data dir
+	Incarnation   string                 `protobuf:"bytes,4,opt,name=incarnation,proto3" json:"incarnation,omitempty"`           // random per chunkserver process
+	Rack          string                 `protobuf:"bytes,5,opt,name=rack,proto3" json:"rack,omitempty"`                         // failure domain (rack or zone)
+	GrpcAddr      string                 `protobuf:"bytes,6,opt,name=grpc_addr,json=grpcAddr,proto3" json:"grpc_addr,omitempty"` // advertised gRPC host:port
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterRequest) Reset() {
+	*x = RegisterRequest{}
+	mi := &file_gfs_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterRequest) ProtoMessage() {}
+
+func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
+func (*RegisterRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RegisterRequest) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetIncarnation() string {
+	if x != nil {
+		return x.Incarnation
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetRack() string {
+	if x != nil {
+		return x.Rack
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetGrpcAddr() string {
+	if x != nil {
+		return x.GrpcAddr
+	}
+	return ""
+}
+
+type RegisterResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterResponse) Reset() {
+	*x = RegisterResponse{}
+	mi := &file_gfs_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterResponse) ProtoMessage() {}
+
+func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
+func (*RegisterResponse) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{2}
+}
+
+type HeartbeatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Port          string                 `protobuf:"bytes,1,opt,name=port,proto3" json:"port,omitempty"`
+	Addr          string                 `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	Uuid          string                 `protobuf:"bytes,3,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Incarnation   string                 `protobuf:"bytes,4,opt,name=incarnation,proto3" json:"incarnation,omitempty"`
+	Rack          string                 `protobuf:"bytes,5,opt,name=rack,proto3" json:"rack,omitempty"`
+	GrpcAddr      string                 `protobuf:"bytes,6,opt,name=grpc_addr,json=grpcAddr,proto3" json:"grpc_addr,omitempty"`
+	Stats         *NodeStats             `protobuf:"bytes,7,opt,name=stats,proto3" json:"stats,omitempty"`
+	Versions      map[string]uint64      `protobuf:"bytes,8,rep,name=versions,proto3" json:"versions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // chunks committed since the last heartbeat
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_gfs_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HeartbeatRequest) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetIncarnation() string {
+	if x != nil {
+		return x.Incarnation
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetRack() string {
+	if x != nil {
+		return x.Rack
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetGrpcAddr() string {
+	if x != nil {
+		return x.GrpcAddr
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetStats() *NodeStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetVersions() map[string]uint64 {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type HeartbeatResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatResponse) Reset() {
+	*x = HeartbeatResponse{}
+	mi := &file_gfs_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatResponse) ProtoMessage() {}
+
+func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
+func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{4}
+}
+
+type AllocateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"` // 0 only creates the file
+	Replication   int32                  `protobuf:"varint,3,opt,name=replication,proto3" json:"replication,omitempty"`              // replicas per chunk for a new file, 0 for the default
+	ChunkSize     int64                  `protobuf:"varint,4,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"` // the caller's chunk size, refused unless it matches; 0 skips the check
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AllocateRequest) Reset() {
+	*x = AllocateRequest{}
+	mi := &file_gfs_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllocateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllocateRequest) ProtoMessage() {}
+
+func (x *AllocateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllocateRequest.ProtoReflect.Descriptor instead.
+func (*AllocateRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AllocateRequest) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *AllocateRequest) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *AllocateRequest) GetReplication() int32 {
+	if x != nil {
+		return x.Replication
+	}
+	return 0
+}
+
+func (x *AllocateRequest) GetChunkSize() int64 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+type ChunkAllocation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkId       string                 `protobuf:"bytes,1,opt,name=chunk_id,json=chunkId,proto3" json:"chunk_id,omitempty"`
+	Locations     []string               `protobuf:"bytes,2,rep,name=locations,proto3" json:"locations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChunkAllocation) Reset() {
+	*x = ChunkAllocation{}
+	mi := &file_gfs_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkAllocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkAllocation) ProtoMessage() {}
+
+func (x *ChunkAllocation) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkAllocation.ProtoReflect.Descriptor instead.
+func (*ChunkAllocation) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ChunkAllocation) GetChunkId() string {
+	if x != nil {
+		return x.ChunkId
+	}
+	return ""
+}
+
+func (x *ChunkAllocation) GetLocations() []string {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+type AllocateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunks        []*ChunkAllocation     `protobuf:"bytes,1,rep,name=chunks,proto3" json:"chunks,omitempty"`
+	ChunkSize     int64                  `protobuf:"varint,2,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AllocateResponse) Reset() {
+	*x = AllocateResponse{}
+	mi := &file_gfs_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllocateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllocateResponse) ProtoMessage() {}
+
+func (x *AllocateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllocateResponse.ProtoReflect.Descriptor instead.
+func (*AllocateResponse) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AllocateResponse) GetChunks() []*ChunkAllocation {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+func (x *AllocateResponse) GetChunkSize() int64 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+type ChunkLocationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkId       string                 `protobuf:"bytes,1,opt,name=chunk_id,json=chunkId,proto3" json:"chunk_id,omitempty"`
+	ClientHost    string                 `protobuf:"bytes,2,opt,name=client_host,json=clientHost,proto3" json:"client_host,omitempty"` // optional locality hint: replicas on this host first
+	ClientRack    string                 `protobuf:"bytes,3,opt,name=client_rack,json=clientRack,proto3" json:"client_rack,omitempty"` // then replicas in this rack
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChunkLocationsRequest) Reset() {
+	*x = ChunkLocationsRequest{}
+	mi := &file_gfs_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkLocationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkLocationsRequest) ProtoMessage() {}
+
+func (x *ChunkLocationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkLocationsRequest.ProtoReflect.Descriptor instead.
+func (*ChunkLocationsRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ChunkLocationsRequest) GetChunkId() string {
+	if x != nil {
+		return x.ChunkId
+	}
+	return ""
+}
+
+func (x *ChunkLocationsRequest) GetClientHost() string {
+	if x != nil {
+		return x.ClientHost
+	}
+	return ""
+}
+
+func (x *ChunkLocationsRequest) GetClientRack() string {
+	if x != nil {
+		return x.ClientRack
+	}
+	return ""
+}
+
+type ChunkLocationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Locations     []string               `protobuf:"bytes,1,rep,name=locations,proto3" json:"locations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChunkLocationsResponse) Reset() {
+	*x = ChunkLocationsResponse{}
+	mi := &file_gfs_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkLocationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkLocationsResponse) ProtoMessage() {}
+
+func (x *ChunkLocationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkLocationsResponse.ProtoReflect.Descriptor instead.
+func (*ChunkLocationsResponse) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ChunkLocationsResponse) GetLocations() []string {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+type PrimaryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkId       string                 `protobuf:"bytes,1,opt,name=chunk_id,json=chunkId,proto3" json:"chunk_id,omitempty"`
+	Preferred     string                 `protobuf:"bytes,2,opt,name=preferred,proto3" json:"preferred,omitempty"` // only used by AssignPrimary
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrimaryRequest) Reset() {
+	*x = PrimaryRequest{}
+	mi := &file_gfs_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrimaryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrimaryRequest) ProtoMessage() {}
+
+func (x *PrimaryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrimaryRequest.ProtoReflect.Descriptor instead.
+func (*PrimaryRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PrimaryRequest) GetChunkId() string {
+	if x != nil {
+		return x.ChunkId
+	}
+	return ""
+}
+
+func (x *PrimaryRequest) GetPreferred() string {
+	if x != nil {
+		return x.Preferred
+	}
+	return ""
+}
+
+type PrimaryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Primary       string                 `protobuf:"bytes,1,opt,name=primary,proto3" json:"primary,omitempty"` // empty when no lease is held
+	LeaseSeconds  int64                  `protobuf:"varint,2,opt,name=lease_seconds,json=leaseSeconds,proto3" json:"lease_seconds,omitempty"`
+	Replicas      []string               `protobuf:"bytes,3,rep,name=replicas,proto3" json:"replicas,omitempty"`
+	Version       uint64                 `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrimaryResponse) Reset() {
+	*x = PrimaryResponse{}
+	mi := &file_gfs_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrimaryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrimaryResponse) ProtoMessage() {}
+
+func (x *PrimaryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrimaryResponse.ProtoReflect.Descriptor instead.
+func (*PrimaryResponse) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PrimaryResponse) GetPrimary() string {
+	if x != nil {
+		return x.Primary
+	}
+	return ""
+}
+
+func (x *PrimaryResponse) GetLeaseSeconds() int64 {
+	if x != nil {
+		return x.LeaseSeconds
+	}
+	return 0
+}
+
+func (x *PrimaryResponse) GetReplicas() []string {
+	if x != nil {
+		return x.Replicas
+	}
+	return nil
+}
+
+func (x *PrimaryResponse) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type RenewLeaseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkId       string                 `protobuf:"bytes,1,opt,name=chunk_id,json=chunkId,proto3" json:"chunk_id,omitempty"`
+	Primary       string                 `protobuf:"bytes,2,opt,name=primary,proto3" json:"primary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewLeaseRequest) Reset() {
+	*x = RenewLeaseRequest{}
+	mi := &file_gfs_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewLeaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewLeaseRequest) ProtoMessage() {}
+
+func (x *RenewLeaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewLeaseRequest.ProtoReflect.Descriptor instead.
+func (*RenewLeaseRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RenewLeaseRequest) GetChunkId() string {
+	if x != nil {
+		return x.ChunkId
+	}
+	return ""
+}
+
+func (x *RenewLeaseRequest) GetPrimary() string {
+	if x != nil {
+		return x.Primary
+	}
+	return ""
+}
+
+type RenewLeaseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	LeaseSeconds  int64                  `protobuf:"varint,2,opt,name=lease_seconds,json=leaseSeconds,proto3" json:"lease_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewLeaseResponse) Reset() {
+	*x = RenewLeaseResponse{}
+	mi := &file_gfs_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewLeaseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewLeaseResponse) ProtoMessage() {}
+
+func (x *RenewLeaseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewLeaseResponse.ProtoReflect.Descriptor instead.
+func (*RenewLeaseResponse) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RenewLeaseResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *RenewLeaseResponse) GetLeaseSeconds() int64 {
+	if x != nil {
+		return x.LeaseSeconds
+	}
+	return 0
+}
+
+type ReportLostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	Uuid          string                 `protobuf:"bytes,2,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Chunks        []string               `protobuf:"bytes,3,rep,name=chunks,proto3" json:"chunks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportLostRequest) Reset() {
+	*x = ReportLostRequest{}
+	mi := &file_gfs_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportLostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportLostRequest) ProtoMessage() {}
+
+func (x *ReportLostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportLostRequest.ProtoReflect.Descriptor instead.
+func (*ReportLostRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ReportLostRequest) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *ReportLostRequest) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *ReportLostRequest) GetChunks() []string {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+type ReportLostResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportLostResponse) Reset() {
+	*x = ReportLostResponse{}
+	mi := &file_gfs_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportLostResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportLostResponse) ProtoMessage() {}
+
+func (x *ReportLostResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportLostResponse.ProtoReflect.Descriptor instead.
+func (*ReportLostResponse) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{15}
+}
+
+type ReportWriteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkId       string                 `protobuf:"bytes,1,opt,name=chunk_id,json=chunkId,proto3" json:"chunk_id,omitempty"`
+	Addr          string                 `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	Length        int64                  `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	Version       uint64                 `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"` // the chunk's version after the write
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportWriteRequest) Reset() {
+	*x = ReportWriteRequest{}
+	mi := &file_gfs_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportWriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportWriteRequest) ProtoMessage() {}
+
+func (x *ReportWriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportWriteRequest.ProtoReflect.Descriptor instead.
+func (*ReportWriteRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ReportWriteRequest) GetChunkId() string {
+	if x != nil {
+		return x.ChunkId
+	}
+	return ""
+}
+
+func (x *ReportWriteRequest) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *ReportWriteRequest) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *ReportWriteRequest) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type ReportWriteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportWriteResponse) Reset() {
+	*x = ReportWriteResponse{}
+	mi := &file_gfs_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportWriteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportWriteResponse) ProtoMessage() {}
+
+func (x *ReportWriteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportWriteResponse.ProtoReflect.Descriptor instead.
+func (*ReportWriteResponse) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{17}
+}
+
+type FileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	ClientHost    string                 `protobuf:"bytes,2,opt,name=client_host,json=clientHost,proto3" json:"client_host,omitempty"` // Lookup only: replicas on this host first
+	ClientRack    string                 `protobuf:"bytes,3,opt,name=client_rack,json=clientRack,proto3" json:"client_rack,omitempty"` // then replicas in this rack
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileRequest) Reset() {
+	*x = FileRequest{}
+	mi := &file_gfs_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileRequest) ProtoMessage() {}
+
+func (x *FileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileRequest.ProtoReflect.Descriptor instead.
+func (*FileRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *FileRequest) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *FileRequest) GetClientHost() string {
+	if x != nil {
+		return x.ClientHost
+	}
+	return ""
+}
+
+func (x *FileRequest) GetClientRack() string {
+	if x != nil {
+		return x.ClientRack
+	}
+	return ""
+}
+
+type FileChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	ChunkId       string                 `protobuf:"bytes,2,opt,name=chunk_id,json=chunkId,proto3" json:"chunk_id,omitempty"`
+	Length        int64                  `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	Locations     []string               `protobuf:"bytes,4,rep,name=locations,proto3" json:"locations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileChunk) Reset() {
+	*x = FileChunk{}
+	mi := &file_gfs_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileChunk) ProtoMessage() {}
+
+func (x *FileChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileChunk.ProtoReflect.Descriptor instead.
+func (*FileChunk) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *FileChunk) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *FileChunk) GetChunkId() string {
+	if x != nil {
+		return x.ChunkId
+	}
+	return ""
+}
+
+func (x *FileChunk) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *FileChunk) GetLocations() []string {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+type LookupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Length        int64                  `protobuf:"varint,2,opt,name=length,proto3" json:"length,omitempty"`
+	ChunkSize     int64                  `protobuf:"varint,3,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	Chunks        []*FileChunk           `protobuf:"bytes,4,rep,name=chunks,proto3" json:"chunks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupResponse) Reset() {
+	*x = LookupResponse{}
+	mi := &file_gfs_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupResponse) ProtoMessage() {}
+
+func (x *LookupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupResponse.ProtoReflect.Descriptor instead.
+func (*LookupResponse) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *LookupResponse) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *LookupResponse) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *LookupResponse) GetChunkSize() int64 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+func (x *LookupResponse) GetChunks() []*FileChunk {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+type StatResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Length        int64                  `protobuf:"varint,2,opt,name=length,proto3" json:"length,omitempty"`
+	ChunkSize     int64                  `protobuf:"varint,3,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	Chunks        int32                  `protobuf:"varint,4,opt,name=chunks,proto3" json:"chunks,omitempty"`
+	Replication   int32                  `protobuf:"varint,5,opt,name=replication,proto3" json:"replication,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatResponse) Reset() {
+	*x = StatResponse{}
+	mi := &file_gfs_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatResponse) ProtoMessage() {}
+
+func (x *StatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatResponse.ProtoReflect.Descriptor instead.
+func (*StatResponse) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *StatResponse) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *StatResponse) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *StatResponse) GetChunkSize() int64 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+func (x *StatResponse) GetChunks() int32 {
+	if x != nil {
+		return x.Chunks
+	}
+	return 0
+}
+
+func (x *StatResponse) GetReplication() int32 {
+	if x != nil {
+		return x.Replication
+	}
+	return 0
+}
+
+type FileChunksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Start         int32                  `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"`
+	Count         int32                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"` // 0 for through the last chunk
+	ClientHost    string                 `protobuf:"bytes,4,opt,name=client_host,json=clientHost,proto3" json:"client_host,omitempty"`
+	ClientRack    string                 `protobuf:"bytes,5,opt,name=client_rack,json=clientRack,proto3" json:"client_rack,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileChunksRequest) Reset() {
+	*x = FileChunksRequest{}
+	mi := &file_gfs_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileChunksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileChunksRequest) ProtoMessage() {}
+
+func (x *FileChunksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileChunksRequest.ProtoReflect.Descriptor instead.
+func (*FileChunksRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *FileChunksRequest) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *FileChunksRequest) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *FileChunksRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *FileChunksRequest) GetClientHost() string {
+	if x != nil {
+		return x.ClientHost
+	}
+	return ""
+}
+
+func (x *FileChunksRequest) GetClientRack() string {
+	if x != nil {
+		return x.ClientRack
+	}
+	return ""
+}
+
+type FileChunksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"` // chunks in the file
+	Chunks        []*FileChunk           `protobuf:"bytes,3,rep,name=chunks,proto3" json:"chunks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileChunksResponse) Reset() {
+	*x = FileChunksResponse{}
+	mi := &file_gfs_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileChunksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileChunksResponse) ProtoMessage() {}
+
+func (x *FileChunksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileChunksResponse.ProtoReflect.Descriptor instead.
+func (*FileChunksResponse) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *FileChunksResponse) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *FileChunksResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *FileChunksResponse) GetChunks() []*FileChunk {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+type SetReplicationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Replication   int32                  `protobuf:"varint,2,opt,name=replication,proto3" json:"replication,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetReplicationRequest) Reset() {
+	*x = SetReplicationRequest{}
+	mi := &file_gfs_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetReplicationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetReplicationRequest) ProtoMessage() {}
+
+func (x *SetReplicationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetReplicationRequest.ProtoReflect.Descriptor instead.
+func (*SetReplicationRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SetReplicationRequest) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *SetReplicationRequest) GetReplication() int32 {
+	if x != nil {
+		return x.Replication
+	}
+	return 0
+}
+
+type SetReplicationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Replication   int32                  `protobuf:"varint,2,opt,name=replication,proto3" json:"replication,omitempty"`
+	Chunks        int32                  `protobuf:"varint,3,opt,name=chunks,proto3" json:"chunks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetReplicationResponse) Reset() {
+	*x = SetReplicationResponse{}
+	mi := &file_gfs_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetReplicationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetReplicationResponse) ProtoMessage() {}
+
+func (x *SetReplicationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetReplicationResponse.ProtoReflect.Descriptor instead.
+func (*SetReplicationResponse) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SetReplicationResponse) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *SetReplicationResponse) GetReplication() int32 {
+	if x != nil {
+		return x.Replication
+	}
+	return 0
+}
+
+func (x *SetReplicationResponse) GetChunks() int32 {
+	if x != nil {
+		return x.Chunks
+	}
+	return 0
+}
+
+type ChunkData struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkId       string                 `protobuf:"bytes,1,opt,name=chunk_id,json=chunkId,proto3" json:"chunk_id,omitempty"`
+	Seq           uint64                 `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`                 // ApplyWrite only
+	Version       uint64                 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`         // ApplyWrite and WriteChunk
+	ReqId         string                 `protobuf:"bytes,4,opt,name=req_id,json=reqId,proto3" json:"req_id,omitempty"` // WritePrimary only, optional idempotency key
+	Data          []byte                 `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChunkData) Reset() {
+	*x = ChunkData{}
+	mi := &file_gfs_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkData) ProtoMessage() {}
+
+func (x *ChunkData) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkData.ProtoReflect.Descriptor instead.
+func (*ChunkData) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ChunkData) GetChunkId() string {
+	if x != nil {
+		return x.ChunkId
+	}
+	return ""
+}
+
+func (x *ChunkData) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *ChunkData) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ChunkData) GetReqId() string {
+	if x != nil {
+		return x.ReqId
+	}
+	return ""
+}
+
+func (x *ChunkData) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type WriteResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteResult) Reset() {
+	*x = WriteResult{}
+	mi := &file_gfs_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteResult) ProtoMessage() {}
+
+func (x *WriteResult) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteResult.ProtoReflect.Descriptor instead.
+func (*WriteResult) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *WriteResult) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+type ReadChunkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkId       string                 `protobuf:"bytes,1,opt,name=chunk_id,json=chunkId,proto3" json:"chunk_id,omitempty"`
+	Offset        int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"` // first byte to read
+	Length        int64                  `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"` // bytes to read, 0 for through the end of the chunk
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadChunkRequest) Reset() {
+	*x = ReadChunkRequest{}
+	mi := &file_gfs_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadChunkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadChunkRequest) ProtoMessage() {}
+
+func (x *ReadChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadChunkRequest.ProtoReflect.Descriptor instead.
+func (*ReadChunkRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ReadChunkRequest) GetChunkId() string {
+	if x != nil {
+		return x.ChunkId
+	}
+	return ""
+}
+
+func (x *ReadChunkRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ReadChunkRequest) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+type CopyChunkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkId       string                 `protobuf:"bytes,1,opt,name=chunk_id,json=chunkId,proto3" json:"chunk_id,omitempty"`
+	Target        string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"` // HTTP host:port of the chunkserver receiving the copy
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CopyChunkRequest) Reset() {
+	*x = CopyChunkRequest{}
+	mi := &file_gfs_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyChunkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyChunkRequest) ProtoMessage() {}
+
+func (x *CopyChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyChunkRequest.ProtoReflect.Descriptor instead.
+func (*CopyChunkRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CopyChunkRequest) GetChunkId() string {
+	if x != nil {
+		return x.ChunkId
+	}
+	return ""
+}
+
+func (x *CopyChunkRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+type CommitRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkId       string                 `protobuf:"bytes,1,opt,name=chunk_id,json=chunkId,proto3" json:"chunk_id,omitempty"`
+	Seq           uint64                 `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitRequest) Reset() {
+	*x = CommitRequest{}
+	mi := &file_gfs_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitRequest) ProtoMessage() {}
+
+func (x *CommitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitRequest.ProtoReflect.Descriptor instead.
+func (*CommitRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *CommitRequest) GetChunkId() string {
+	if x != nil {
+		return x.ChunkId
+	}
+	return ""
+}
+
+func (x *CommitRequest) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+type DeleteChunkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkId       string                 `protobuf:"bytes,1,opt,name=chunk_id,json=chunkId,proto3" json:"chunk_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteChunkRequest) Reset() {
+	*x = DeleteChunkRequest{}
+	mi := &file_gfs_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteChunkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteChunkRequest) ProtoMessage() {}
+
+func (x *DeleteChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteChunkRequest.ProtoReflect.Descriptor instead.
+func (*DeleteChunkRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *DeleteChunkRequest) GetChunkId() string {
+	if x != nil {
+		return x.ChunkId
+	}
+	return ""
+}
+
+type ChunkReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChunkReportRequest) Reset() {
+	*x = ChunkReportRequest{}
+	mi := &file_gfs_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkReportRequest) ProtoMessage() {}
+
+func (x *ChunkReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkReportRequest.ProtoReflect.Descriptor instead.
+func (*ChunkReportRequest) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{32}
+}
+
+type ChunkReportResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Chunks        []string               `protobuf:"bytes,2,rep,name=chunks,proto3" json:"chunks,omitempty"`
+	Versions      map[string]uint64      `protobuf:"bytes,3,rep,name=versions,proto3" json:"versions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // chunk ID -> version, 0 if unknown
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChunkReportResponse) Reset() {
+	*x = ChunkReportResponse{}
+	mi := &file_gfs_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkReportResponse) ProtoMessage() {}
+
+func (x *ChunkReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gfs_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkReportResponse.ProtoReflect.Descriptor instead.
+func (*ChunkReportResponse) Descriptor() ([]byte, []int) {
+	return file_gfs_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ChunkReportResponse) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *ChunkReportResponse) GetChunks() []string {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+func (x *ChunkReportResponse) GetVersions() map[string]uint64 {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+var File_gfs_proto protoreflect.FileDescriptor
+
+const file_gfs_proto_rawDesc = "" +
+	"\n" +
+	"\tgfs.proto\x12\agfs.rpc\"\xdb\x01\n" +
+	"\tNodeStats\x12\x1f\n" +
+	"\vtotal_bytes\x18\x01 \x01(\x04R\n" +
+	"totalBytes\x12\x1d\n" +
+	"\n" +
+	"free_bytes\x18\x02 \x01(\x04R\tfreeBytes\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x03 \x01(\x04R\tusedBytes\x12\x1f\n" +
+	"\vchunk_count\x18\x04 \x01(\x03R\n" +
+	"chunkCount\x12%\n" +
+	"\x0einflight_reads\x18\x05 \x01(\x03R\rinflightReads\x12'\n" +
+	"\x0finflight_writes\x18\x06 \x01(\x03R\x0einflightWrites\"\xa0\x01\n" +
+	"\x0fRegisterRequest\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\tR\x04port\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\x12\x12\n" +
+	"\x04uuid\x18\x03 \x01(\tR\x04uuid\x12 \n" +
+	"\vincarnation\x18\x04 \x01(\tR\vincarnation\x12\x12\n" +
+	"\x04rack\x18\x05 \x01(\tR\x04rack\x12\x1b\n" +
+	"\tgrpc_addr\x18\x06 \x01(\tR\bgrpcAddr\"\x12\n" +
+	"\x10RegisterResponse\"\xcd\x02\n" +
+	"\x10HeartbeatRequest\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\tR\x04port\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\x12\x12\n" +
+	"\x04uuid\x18\x03 \x01(\tR\x04uuid\x12 \n" +
+	"\vincarnation\x18\x04 \x01(\tR\vincarnation\x12\x12\n" +
+	"\x04rack\x18\x05 \x01(\tR\x04rack\x12\x1b\n" +
+	"\tgrpc_addr\x18\x06 \x01(\tR\bgrpcAddr\x12(\n" +
+	"\x05stats\x18\a \x01(\v2\x12.gfs.rpc.NodeStatsR\x05stats\x12C\n" +
+	"\bversions\x18\b \x03(\v2'.gfs.rpc.HeartbeatRequest.VersionsEntryR\bversions\x1a;\n" +
+	"\rVersionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x04R\x05value:\x028\x01\"\x13\n" +
+	"\x11HeartbeatResponse\"\x85\x01\n" +
+	"\x0fAllocateRequest\x12\x12\n" +
+	"\x04file\x18\x01 \x01(\tR\x04file\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x02 \x01(\x03R\tsizeBytes\x12 \n" +
+	"\vreplication\x18\x03 \x01(\x05R\vreplication\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x04 \x01(\x03R\tchunkSize\"J\n" +
+	"\x0fChunkAllocation\x12\x19\n" +
+	"\bchunk_id\x18\x01 \x01(\tR\achunkId\x12\x1c\n" +
+	"\tlocations\x18\x02 \x03(\tR\tlocations\"c\n" +
+	"\x10AllocateResponse\x120\n" +
+	"\x06chunks\x18\x01 \x03(\v2\x18.gfs.rpc.ChunkAllocationR\x06chunks\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x02 \x01(\x03R\tchunkSize\"t\n" +
+	"\x15ChunkLocationsRequest\x12\x19\n" +
+	"\bchunk_id\x18\x01 \x01(\tR\achunkId\x12\x1f\n" +
+	"\vclient_host\x18\x02 \x01(\tR\n" +
+	"clientHost\x12\x1f\n" +
+	"\vclient_rack\x18\x03 \x01(\tR\n" +
+	"clientRack\"6\n" +
+	"\x16ChunkLocationsResponse\x12\x1c\n" +
+	"\tlocations\x18\x01 \x03(\tR\tlocations\"I\n" +
+	"\x0ePrimaryRequest\x12\x19\n" +
+	"\bchunk_id\x18\x01 \x01(\tR\achunkId\x12\x1c\n" +
+	"\tpreferred\x18\x02 \x01(\tR\tpreferred\"\x86\x01\n" +
+	"\x0fPrimaryResponse\x12\x18\n" +
+	"\aprimary\x18\x01 \x01(\tR\aprimary\x12#\n" +
+	"\rlease_seconds\x18\x02 \x01(\x03R\fleaseSeconds\x12\x1a\n" +
+	"\breplicas\x18\x03 \x03(\tR\breplicas\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x04R\aversion\"H\n" +
+	"\x11RenewLeaseRequest\x12\x19\n" +
+	"\bchunk_id\x18\x01 \x01(\tR\achunkId\x12\x18\n" +
+	"\aprimary\x18\x02 \x01(\tR\aprimary\"I\n" +
+	"\x12RenewLeaseResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12#\n" +
+	"\rlease_seconds\x18\x02 \x01(\x03R\fleaseSeconds\"S\n" +
+	"\x11ReportLostRequest\x12\x12\n" +
+	"\x04addr\x18\x01 \x01(\tR\x04addr\x12\x12\n" +
+	"\x04uuid\x18\x02 \x01(\tR\x04uuid\x12\x16\n" +
+	"\x06chunks\x18\x03 \x03(\tR\x06chunks\"\x14\n" +
+	"\x12ReportLostResponse\"u\n" +
+	"\x12ReportWriteRequest\x12\x19\n" +
+	"\bchunk_id\x18\x01 \x01(\tR\achunkId\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x03R\x06length\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x04R\aversion\"\x15\n" +
+	"\x13ReportWriteResponse\"c\n" +
+	"\vFileRequest\x12\x12\n" +
+	"\x04file\x18\x01 \x01(\tR\x04file\x12\x1f\n" +
+	"\vclient_host\x18\x02 \x01(\tR\n" +
+	"clientHost\x12\x1f\n" +
+	"\vclient_rack\x18\x03 \x01(\tR\n" +
+	"clientRack\"r\n" +
+	"\tFileChunk\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x19\n" +
+	"\bchunk_id\x18\x02 \x01(\tR\achunkId\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x03R\x06length\x12\x1c\n" +
+	"\tlocations\x18\x04 \x03(\tR\tlocations\"\x87\x01\n" +
+	"\x0eLookupResponse\x12\x12\n" +
+	"\x04file\x18\x01 \x01(\tR\x04file\x12\x16\n" +
+	"\x06length\x18\x02 \x01(\x03R\x06length\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x03 \x01(\x03R\tchunkSize\x12*\n" +
+	"\x06chunks\x18\x04 \x03(\v2\x12.gfs.rpc.FileChunkR\x06chunks\"\x93\x01\n" +
+	"\fStatResponse\x12\x12\n" +
+	"\x04file\x18\x01 \x01(\tR\x04file\x12\x16\n" +
+	"\x06length\x18\x02 \x01(\x03R\x06length\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x03 \x01(\x03R\tchunkSize\x12\x16\n" +
+	"\x06chunks\x18\x04 \x01(\x05R\x06chunks\x12 \n" +
+	"\vreplication\x18\x05 \x01(\x05R\vreplication\"\x95\x01\n" +
+	"\x11FileChunksRequest\x12\x12\n" +
+	"\x04file\x18\x01 \x01(\tR\x04file\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\x05R\x05start\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x05R\x05count\x12\x1f\n" +
+	"\vclient_host\x18\x04 \x01(\tR\n" +
+	"clientHost\x12\x1f\n" +
+	"\vclient_rack\x18\x05 \x01(\tR\n" +
+	"clientRack\"j\n" +
+	"\x12FileChunksResponse\x12\x12\n" +
+	"\x04file\x18\x01 \x01(\tR\x04file\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12*\n" +
+	"\x06chunks\x18\x03 \x03(\v2\x12.gfs.rpc.FileChunkR\x06chunks\"M\n" +
+	"\x15SetReplicationRequest\x12\x12\n" +
+	"\x04file\x18\x01 \x01(\tR\x04file\x12 \n" +
+	"\vreplication\x18\x02 \x01(\x05R\vreplication\"f\n" +
+	"\x16SetReplicationResponse\x12\x12\n" +
+	"\x04file\x18\x01 \x01(\tR\x04file\x12 \n" +
+	"\vreplication\x18\x02 \x01(\x05R\vreplication\x12\x16\n" +
+	"\x06chunks\x18\x03 \x01(\x05R\x06chunks\"}\n" +
+	"\tChunkData\x12\x19\n" +
+	"\bchunk_id\x18\x01 \x01(\tR\achunkId\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x04R\x03seq\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x04R\aversion\x12\x15\n" +
+	"\x06req_id\x18\x04 \x01(\tR\x05reqId\x12\x12\n" +
+	"\x04data\x18\x05 \x01(\fR\x04data\"\x1f\n" +
+	"\vWriteResult\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\"]\n" +
+	"\x10ReadChunkRequest\x12\x19\n" +
+	"\bchunk_id\x18\x01 \x01(\tR\achunkId\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x03R\x06length\"E\n" +
+	"\x10CopyChunkRequest\x12\x19\n" +
+	"\bchunk_id\x18\x01 \x01(\tR\achunkId\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\"<\n" +
+	"\rCommitRequest\x12\x19\n" +
+	"\bchunk_id\x18\x01 \x01(\tR\achunkId\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x04R\x03seq\"/\n" +
+	"\x12DeleteChunkRequest\x12\x19\n" +
+	"\bchunk_id\x18\x01 \x01(\tR\achunkId\"\x14\n" +
+	"\x12ChunkReportRequest\"\xc6\x01\n" +
+	"\x13ChunkReportResponse\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x16\n" +
+	"\x06chunks\x18\x02 \x03(\tR\x06chunks\x12F\n" +
+	"\bversions\x18\x03 \x03(\v2*.gfs.rpc.ChunkReportResponse.VersionsEntryR\bversions\x1a;\n" +
+	"\rVersionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x04R\x05value:\x028\x012\x86\a\n" +
+	"\x06Master\x12?\n" +
+	"\bRegister\x12\x18.gfs.rpc.RegisterRequest\x1a\x19.gfs.rpc.RegisterResponse\x12B\n" +
+	"\tHeartbeat\x12\x19.gfs.rpc.HeartbeatRequest\x1a\x1a.gfs.rpc.HeartbeatResponse\x12?\n" +
+	"\bAllocate\x12\x18.gfs.rpc.AllocateRequest\x1a\x19.gfs.rpc.AllocateResponse\x12Q\n" +
+	"\x0eChunkLocations\x12\x1e.gfs.rpc.ChunkLocationsRequest\x1a\x1f.gfs.rpc.ChunkLocationsResponse\x12?\n" +
+	"\n" +
+	"GetPrimary\x12\x17.gfs.rpc.PrimaryRequest\x1a\x18.gfs.rpc.PrimaryResponse\x12B\n" +
+	"\rAssignPrimary\x12\x17.gfs.rpc.PrimaryRequest\x1a\x18.gfs.rpc.PrimaryResponse\x12E\n" +
+	"\n" +
+	"RenewLease\x12\x1a.gfs.rpc.RenewLeaseRequest\x1a\x1b.gfs.rpc.RenewLeaseResponse\x12E\n" +
+	"\n" +
+	"ReportLost\x12\x1a.gfs.rpc.ReportLostRequest\x1a\x1b.gfs.rpc.ReportLostResponse\x12H\n" +
+	"\vReportWrite\x12\x1b.gfs.rpc.ReportWriteRequest\x1a\x1c.gfs.rpc.ReportWriteResponse\x127\n" +
+	"\x06Lookup\x12\x14.gfs.rpc.FileRequest\x1a\x17.gfs.rpc.LookupResponse\x123\n" +
+	"\x04Stat\x12\x14.gfs.rpc.FileRequest\x1a\x15.gfs.rpc.StatResponse\x12E\n" +
+	"\n" +
+	"FileChunks\x12\x1a.gfs.rpc.FileChunksRequest\x1a\x1b.gfs.rpc.FileChunksResponse\x12Q\n" +
+	"\x0eSetReplication\x12\x1e.gfs.rpc.SetReplicationRequest\x1a\x1f.gfs.rpc.SetReplicationResponse2\xfd\x03\n" +
+	"\vChunkServer\x128\n" +
+	"\n" +
+	"WriteChunk\x12\x12.gfs.rpc.ChunkData\x1a\x14.gfs.rpc.WriteResult(\x01\x12<\n" +
+	"\tReadChunk\x12\x19.gfs.rpc.ReadChunkRequest\x1a\x12.gfs.rpc.ChunkData0\x01\x12:\n" +
+	"\fWritePrimary\x12\x12.gfs.rpc.ChunkData\x1a\x14.gfs.rpc.WriteResult(\x01\x128\n" +
+	"\n" +
+	"ApplyWrite\x12\x12.gfs.rpc.ChunkData\x1a\x14.gfs.rpc.WriteResult(\x01\x126\n" +
+	"\x06Commit\x12\x16.gfs.rpc.CommitRequest\x1a\x14.gfs.rpc.WriteResult\x12<\n" +
+	"\tCopyChunk\x12\x19.gfs.rpc.CopyChunkRequest\x1a\x14.gfs.rpc.WriteResult\x12@\n" +
+	"\vDeleteChunk\x12\x1b.gfs.rpc.DeleteChunkRequest\x1a\x14.gfs.rpc.WriteResult\x12H\n" +
+	"\vChunkReport\x12\x1b.gfs.rpc.ChunkReportRequest\x1a\x1c.gfs.rpc.ChunkReportResponseB\x16Z\x14gfs/internal/rpc;rpcb\x06proto3"
+
+var (
+	file_gfs_proto_rawDescOnce sync.Once
+	file_gfs_proto_rawDescData []byte
+)
+
+func file_gfs_proto_rawDescGZIP() []byte {
+	file_gfs_proto_rawDescOnce.Do(func() {
+		file_gfs_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_gfs_proto_rawDesc), len(file_gfs_proto_rawDesc)))
+	})
+	return file_gfs_proto_rawDescData
+}
+
+var file_gfs_proto_msgTypes = make([]protoimpl.MessageInfo, 36)
+var file_gfs_proto_goTypes = []any{
+	(*NodeStats)(nil),              // 0: gfs.rpc.NodeStats
+	(*RegisterRequest)(nil),        // 1: gfs.rpc.RegisterRequest
+	(*RegisterResponse)(nil),       // 2: gfs.rpc.RegisterResponse
+	(*HeartbeatRequest)(nil),       // 3: gfs.rpc.HeartbeatRequest
+	(*HeartbeatResponse)(nil),      // 4: gfs.rpc.HeartbeatResponse
+	(*AllocateRequest)(nil),        // 5: gfs.rpc.AllocateRequest
+	(*ChunkAllocation)(nil),        // 6: gfs.rpc.ChunkAllocation
+	(*AllocateResponse)(nil),       // 7: gfs.rpc.AllocateResponse
+	(*ChunkLocationsRequest)(nil),  // 8: gfs.rpc.ChunkLocationsRequest
+	(*ChunkLocationsResponse)(nil), // 9: gfs.rpc.ChunkLocationsResponse
+	(*PrimaryRequest)(nil),         // 10: gfs.rpc.PrimaryRequest
+	(*PrimaryResponse)(nil),        // 11: gfs.rpc.PrimaryResponse
+	(*RenewLeaseRequest)(nil),      // 12: gfs.rpc.RenewLeaseRequest
+	(*RenewLeaseResponse)(nil),     // 13: gfs.rpc.RenewLeaseResponse
+	(*ReportLostRequest)(nil),      // 14: gfs.rpc.ReportLostRequest
+	(*ReportLostResponse)(nil),     // 15: gfs.rpc.ReportLostResponse
+	(*ReportWriteRequest)(nil),     // 16: gfs.rpc.ReportWriteRequest
+	(*ReportWriteResponse)(nil),    // 17: gfs.rpc.ReportWriteResponse
+	(*FileRequest)(nil),            // 18: gfs.rpc.FileRequest
+	(*FileChunk)(nil),              // 19: gfs.rpc.FileChunk
+	(*LookupResponse)(nil),         // 20: gfs.rpc.LookupResponse
+	(*StatResponse)(nil),           // 21: gfs.rpc.StatResponse
+	(*FileChunksRequest)(nil),      // 22: gfs.rpc.FileChunksRequest
+	(*FileChunksResponse)(nil),     // 23: gfs.rpc.FileChunksResponse
+	(*SetReplicationRequest)(nil),  // 24: gfs.rpc.SetReplicationRequest
+	(*SetReplicationResponse)(nil), // 25: gfs.rpc.SetReplicationResponse
+	(*ChunkData)(nil),              // 26: gfs.rpc.ChunkData
+	(*WriteResult)(nil),            // 27: gfs.rpc.WriteResult
+	(*ReadChunkRequest)(nil),       // 28: gfs.rpc.ReadChunkRequest
+	(*CopyChunkRequest)(nil),       // 29: gfs.rpc.CopyChunkRequest
+	(*CommitRequest)(nil),          // 30: gfs.rpc.CommitRequest
+	(*DeleteChunkRequest)(nil),     // 31: gfs.rpc.DeleteChunkRequest
+	(*ChunkReportRequest)(nil),     // 32: gfs.rpc.ChunkReportRequest
+	(*ChunkReportResponse)(nil),    // 33: gfs.rpc.ChunkReportResponse
+	nil,                            // 34: gfs.rpc.HeartbeatRequest.VersionsEntry
+	nil,                            // 35: gfs.rpc.ChunkReportResponse.VersionsEntry
+}
+var file_gfs_proto_depIdxs = []int32{
+	0,  // 0: gfs.rpc.HeartbeatRequest.stats:type_name -> gfs.rpc.NodeStats
+	34, // 1: gfs.rpc.HeartbeatRequest.versions:type_name -> gfs.rpc.HeartbeatRequest.VersionsEntry
+	6,  // 2: gfs.rpc.AllocateResponse.chunks:type_name -> gfs.rpc.ChunkAllocation
+	19, // 3: gfs.rpc.LookupResponse.chunks:type_name -> gfs.rpc.FileChunk
+	19, // 4: gfs.rpc.FileChunksResponse.chunks:type_name -> gfs.rpc.FileChunk
+	35, // 5: gfs.rpc.ChunkReportResponse.versions:type_name -> gfs.rpc.ChunkReportResponse.VersionsEntry
+	1,  // 6: gfs.rpc.Master.Register:input_type -> gfs.rpc.RegisterRequest
+	3,  // 7: gfs.rpc.Master.Heartbeat:input_type -> gfs.rpc.HeartbeatRequest
+	5,  // 8: gfs.rpc.Master.Allocate:input_type -> gfs.rpc.AllocateRequest
+	8,  // 9: gfs.rpc.Master.ChunkLocations:input_type -> gfs.rpc.ChunkLocationsRequest
+	10, // 10: gfs.rpc.Master.GetPrimary:input_type -> gfs.rpc.PrimaryRequest
+	10, // 11: gfs.rpc.Master.AssignPrimary:input_type -> gfs.rpc.PrimaryRequest
+	12, // 12: gfs.rpc.Master.RenewLease:input_type -> gfs.rpc.RenewLeaseRequest
+	14, // 13: gfs.rpc.Master.ReportLost:input_type -> gfs.rpc.ReportLostRequest
+	16, // 14: gfs.rpc.Master.ReportWrite:input_type -> gfs.rpc.ReportWriteRequest
+	18, // 15: gfs.rpc.Master.Lookup:input_type -> gfs.rpc.FileRequest
+	18, // 16: gfs.rpc.Master.Stat:input_type -> gfs.rpc.FileRequest
+	22, // 17: gfs.rpc.Master.FileChunks:input_type -> gfs.rpc.FileChunksRequest
+	24, // 18: gfs.rpc.Master.SetReplication:input_type -> gfs.rpc.SetReplicationRequest
+	26, // 19: gfs.rpc.ChunkServer.WriteChunk:input_type -> gfs.rpc.ChunkData
+	28, // 20: gfs.rpc.ChunkServer.ReadChunk:input_type -> gfs.rpc.ReadChunkRequest
+	26, // 21: gfs.rpc.ChunkServer.WritePrimary:input_type -> gfs.rpc.ChunkData
+	26, // 22: gfs.rpc.ChunkServer.ApplyWrite:input_type -> gfs.rpc.ChunkData
+	30, // 23: gfs.rpc.ChunkServer.Commit:input_type -> gfs.rpc.CommitRequest
+	29, // 24: gfs.rpc.ChunkServer.CopyChunk:input_type -> gfs.rpc.CopyChunkRequest
+	31, // 25: gfs.rpc.ChunkServer.DeleteChunk:input_type -> gfs.rpc.DeleteChunkRequest
+	32, // 26: gfs.rpc.ChunkServer.ChunkReport:input_type -> gfs.rpc.ChunkReportRequest
+	2,  // 27: gfs.rpc.Master.Register:output_type -> gfs.rpc.RegisterResponse
+	4,  // 28: gfs.rpc.Master.Heartbeat:output_type -> gfs.rpc.HeartbeatResponse
+	7,  // 29: gfs.rpc.Master.Allocate:output_type -> gfs.rpc.AllocateResponse
+	9,  // 30: gfs.rpc.Master.ChunkLocations:output_type -> gfs.rpc.ChunkLocationsResponse
+	11, // 31: gfs.rpc.Master.GetPrimary:output_type -> gfs.rpc.PrimaryResponse
+	11, // 32: gfs.rpc.Master.AssignPrimary:output_type -> gfs.rpc.PrimaryResponse
+	13, // 33: gfs.rpc.Master.RenewLease:output_type -> gfs.rpc.RenewLeaseResponse
+	15, // 34: gfs.rpc.Master.ReportLost:output_type -> gfs.rpc.ReportLostResponse
+	17, // 35: gfs.rpc.Master.ReportWrite:output_type -> gfs.rpc.ReportWriteResponse
+	20, // 36: gfs.rpc.Master.Lookup:output_type -> gfs.rpc.LookupResponse
+	21, // 37: gfs.rpc.Master.Stat:output_type -> gfs.rpc.StatResponse
+	23, // 38: gfs.rpc.Master.FileChunks:output_type -> gfs.rpc.FileChunksResponse
+	25, // 39: gfs.rpc.Master.SetReplication:output_type -> gfs.rpc.SetReplicationResponse
+	27, // 40: gfs.rpc.ChunkServer.WriteChunk:output_type -> gfs.rpc.WriteResult
+	26, // 41: gfs.rpc.ChunkServer.ReadChunk:output_type -> gfs.rpc.ChunkData
+	27, // 42: gfs.rpc.ChunkServer.WritePrimary:output_type -> gfs.rpc.WriteResult
+	27, // 43: gfs.rpc.ChunkServer.ApplyWrite:output_type -> gfs.rpc.WriteResult
+	27, // 44: gfs.rpc.ChunkServer.Commit:output_type -> gfs.rpc.WriteResult
+	27, // 45: gfs.rpc.ChunkServer.CopyChunk:output_type -> gfs.rpc.WriteResult
+	27, // 46: gfs.rpc.ChunkServer.DeleteChunk:output_type -> gfs.rpc.WriteResult
+	33, // 47: gfs.rpc.ChunkServer.ChunkReport:output_type -> gfs.rpc.ChunkReportResponse
+	27, // [27:48] is the sub-list for method output_type
+	6,  // [6:27] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_gfs_proto_init() }
+func file_gfs_proto_init() {
+	if File_gfs_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_gfs_proto_rawDesc), len(file_gfs_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   36,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_gfs_proto_goTypes,
+		DependencyIndexes: file_gfs_proto_depIdxs,
+		MessageInfos:      file_gfs_proto_msgTypes,
+	}.Build()
+	File_gfs_proto = out.File
+	file_gfs_proto_goTypes = nil
+	file_gfs_proto_depIdxs = nil
+}
@@ -0,0 +1,940 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: gfs.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Master_Register_FullMethodName       = "/gfs.rpc.Master/Register"
+	Master_Heartbeat_FullMethodName      = "/gfs.rpc.Master/Heartbeat"
+	Master_Allocate_FullMethodName       = "/gfs.rpc.Master/Allocate"
+	Master_ChunkLocations_FullMethodName = "/gfs.rpc.Master/ChunkLocations"
+	Master_GetPrimary_FullMethodName     = "/gfs.rpc.Master/GetPrimary"
+	Master_AssignPrimary_FullMethodName  = "/gfs.rpc.Master/AssignPrimary"
+	Master_RenewLease_FullMethodName     = "/gfs.rpc.Master/RenewLease"
+	Master_ReportLost_FullMethodName     = "/gfs.rpc.Master/ReportLost"
+	Master_ReportWrite_FullMethodName    = "/gfs.rpc.Master/ReportWrite"
+	Master_Lookup_FullMethodName         = "/gfs.rpc.Master/Lookup"
+	Master_Stat_FullMethodName           = "/gfs.rpc.Master/Stat"
+	Master_FileChunks_FullMethodName     = "/gfs.rpc.Master/FileChunks"
+	Master_SetReplication_FullMethodName = "/gfs.rpc.Master/SetReplication"
+)
+
+// MasterClient is the client API for Master service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Master is the control plane: chunkserver membership, chunk allocation,
+// locations, primary leases and file lookups.
+type MasterClient interface {
+	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	Allocate(ctx context.Context, in *AllocateRequest, opts ...grpc.CallOption) (*AllocateResponse, error)
+	ChunkLocations(ctx context.Context, in *ChunkLocationsRequest, opts ...grpc.CallOption) (*ChunkLocationsResponse, error)
+	GetPrimary(ctx context.Context, in *PrimaryRequest, opts ...grpc.CallOption) (*PrimaryResponse, error)
+	AssignPrimary(ctx context.Context, in *PrimaryRequest, opts ...grpc.CallOption) (*PrimaryResponse, error)
+	RenewLease(ctx context.Context, in *RenewLeaseRequest, opts ...grpc.CallOption) (*RenewLeaseResponse, error)
+	ReportLost(ctx context.Context, in *ReportLostRequest, opts ...grpc.CallOption) (*ReportLostResponse, error)
+	ReportWrite(ctx context.Context, in *ReportWriteRequest, opts ...grpc.CallOption) (*ReportWriteResponse, error)
+	Lookup(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*LookupResponse, error)
+	Stat(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*StatResponse, error)
+	FileChunks(ctx context.Context, in *FileChunksRequest, opts ...grpc.CallOption) (*FileChunksResponse, error)
+	SetReplication(ctx context.Context, in *SetReplicationRequest, opts ...grpc.CallOption) (*SetReplicationResponse, error)
+}
+
+type masterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMasterClient(cc grpc.ClientConnInterface) MasterClient {
+	return &masterClient{cc}
+}
+
+func (c *masterClient) Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterResponse)
+	err := c.cc.Invoke(ctx, Master_Register_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, Master_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) Allocate(ctx context.Context, in *AllocateRequest, opts ...grpc.CallOption) (*AllocateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AllocateResponse)
+	err := c.cc.Invoke(ctx, Master_Allocate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) ChunkLocations(ctx context.Context, in *ChunkLocationsRequest, opts ...grpc.CallOption) (*ChunkLocationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChunkLocationsResponse)
+	err := c.cc.Invoke(ctx, Master_ChunkLocations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) GetPrimary(ctx context.Context, in *PrimaryRequest, opts ...grpc.CallOption) (*PrimaryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PrimaryResponse)
+	err := c.cc.Invoke(ctx, Master_GetPrimary_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) AssignPrimary(ctx context.Context, in *PrimaryRequest, opts ...grpc.CallOption) (*PrimaryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PrimaryResponse)
+	err := c.cc.Invoke(ctx, Master_AssignPrimary_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) RenewLease(ctx context.Context, in *RenewLeaseRequest, opts ...grpc.CallOption) (*RenewLeaseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenewLeaseResponse)
+	err := c.cc.Invoke(ctx, Master_RenewLease_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) ReportLost(ctx context.Context, in *ReportLostRequest, opts ...grpc.CallOption) (*ReportLostResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportLostResponse)
+	err := c.cc.Invoke(ctx, Master_ReportLost_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) ReportWrite(ctx context.Context, in *ReportWriteRequest, opts ...grpc.CallOption) (*ReportWriteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportWriteResponse)
+	err := c.cc.Invoke(ctx, Master_ReportWrite_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) Lookup(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*LookupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupResponse)
+	err := c.cc.Invoke(ctx, Master_Lookup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) Stat(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*StatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatResponse)
+	err := c.cc.Invoke(ctx, Master_Stat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) FileChunks(ctx context.Context, in *FileChunksRequest, opts ...grpc.CallOption) (*FileChunksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileChunksResponse)
+	err := c.cc.Invoke(ctx, Master_FileChunks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) SetReplication(ctx context.Context, in *SetReplicationRequest, opts ...grpc.CallOption) (*SetReplicationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetReplicationResponse)
+	err := c.cc.Invoke(ctx, Master_SetReplication_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MasterServer is the server API for Master service.
+// All implementations must embed UnimplementedMasterServer
+// for forward compatibility.
+//
+// Master is the control plane: chunkserver membership, chunk allocation,
+// locations, primary leases and file lookups.
+type MasterServer interface {
+	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	Allocate(context.Context, *AllocateRequest) (*AllocateResponse, error)
+	ChunkLocations(context.Context, *ChunkLocationsRequest) (*ChunkLocationsResponse, error)
+	GetPrimary(context.Context, *PrimaryRequest) (*PrimaryResponse, error)
+	AssignPrimary(context.Context, *PrimaryRequest) (*PrimaryResponse, error)
+	RenewLease(context.Context, *RenewLeaseRequest) (*RenewLeaseResponse, error)
+	ReportLost(context.Context, *ReportLostRequest) (*ReportLostResponse, error)
+	ReportWrite(context.Context, *ReportWriteRequest) (*ReportWriteResponse, error)
+	Lookup(context.Context, *FileRequest) (*LookupResponse, error)
+	Stat(context.Context, *FileRequest) (*StatResponse, error)
+	FileChunks(context.Context, *FileChunksRequest) (*FileChunksResponse, error)
+	SetReplication(context.Context, *SetReplicationRequest) (*SetReplicationResponse, error)
+	mustEmbedUnimplementedMasterServer()
+}
+
+// UnimplementedMasterServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMasterServer struct{}
+
+func (UnimplementedMasterServer) Register(context.Context, *RegisterRequest) (*RegisterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (UnimplementedMasterServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedMasterServer) Allocate(context.Context, *AllocateRequest) (*AllocateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Allocate not implemented")
+}
+func (UnimplementedMasterServer) ChunkLocations(context.Context, *ChunkLocationsRequest) (*ChunkLocationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChunkLocations not implemented")
+}
+func (UnimplementedMasterServer) GetPrimary(context.Context, *PrimaryRequest) (*PrimaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPrimary not implemented")
+}
+func (UnimplementedMasterServer) AssignPrimary(context.Context, *PrimaryRequest) (*PrimaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AssignPrimary not implemented")
+}
+func (UnimplementedMasterServer) RenewLease(context.Context, *RenewLeaseRequest) (*RenewLeaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenewLease not implemented")
+}
+func (UnimplementedMasterServer) ReportLost(context.Context, *ReportLostRequest) (*ReportLostResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportLost not implemented")
+}
+func (UnimplementedMasterServer) ReportWrite(context.Context, *ReportWriteRequest) (*ReportWriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportWrite not implemented")
+}
+func (UnimplementedMasterServer) Lookup(context.Context, *FileRequest) (*LookupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Lookup not implemented")
+}
+func (UnimplementedMasterServer) Stat(context.Context, *FileRequest) (*StatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stat not implemented")
+}
+func (UnimplementedMasterServer) FileChunks(context.Context, *FileChunksRequest) (*FileChunksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FileChunks not implemented")
+}
+func (UnimplementedMasterServer) SetReplication(context.Context, *SetReplicationRequest) (*SetReplicationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetReplication not implemented")
+}
+func (UnimplementedMasterServer) mustEmbedUnimplementedMasterServer() {}
+func (UnimplementedMasterServer) testEmbeddedByValue()                {}
+
+// UnsafeMasterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MasterServer will
+// result in compilation errors.
+type UnsafeMasterServer interface {
+	mustEmbedUnimplementedMasterServer()
+}
+
+func RegisterMasterServer(s grpc.ServiceRegistrar, srv MasterServer) {
+	// If the following call pancis, it indicates UnimplementedMasterServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Master_ServiceDesc, srv)
+}
+
+func _Master_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_Register_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).Register(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_Allocate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AllocateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).Allocate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_Allocate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).Allocate(ctx, req.(*AllocateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_ChunkLocations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChunkLocationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).ChunkLocations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_ChunkLocations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).ChunkLocations(ctx, req.(*ChunkLocationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_GetPrimary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrimaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).GetPrimary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_GetPrimary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).GetPrimary(ctx, req.(*PrimaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_AssignPrimary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrimaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).AssignPrimary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_AssignPrimary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).AssignPrimary(ctx, req.(*PrimaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_RenewLease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenewLeaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).RenewLease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_RenewLease_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).RenewLease(ctx, req.(*RenewLeaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_ReportLost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportLostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).ReportLost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_ReportLost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).ReportLost(ctx, req.(*ReportLostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_ReportWrite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportWriteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).ReportWrite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_ReportWrite_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).ReportWrite(ctx, req.(*ReportWriteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_Lookup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).Lookup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_Lookup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).Lookup(ctx, req.(*FileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_Stat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).Stat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_Stat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).Stat(ctx, req.(*FileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_FileChunks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileChunksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).FileChunks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_FileChunks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).FileChunks(ctx, req.(*FileChunksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_SetReplication_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetReplicationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).SetReplication(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_SetReplication_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).SetReplication(ctx, req.(*SetReplicationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Master_ServiceDesc is the grpc.ServiceDesc for Master service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Master_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gfs.rpc.Master",
+	HandlerType: (*MasterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Register",
+			Handler:    _Master_Register_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _Master_Heartbeat_Handler,
+		},
+		{
+			MethodName: "Allocate",
+			Handler:    _Master_Allocate_Handler,
+		},
+		{
+			MethodName: "ChunkLocations",
+			Handler:    _Master_ChunkLocations_Handler,
+		},
+		{
+			MethodName: "GetPrimary",
+			Handler:    _Master_GetPrimary_Handler,
+		},
+		{
+			MethodName: "AssignPrimary",
+			Handler:    _Master_AssignPrimary_Handler,
+		},
+		{
+			MethodName: "RenewLease",
+			Handler:    _Master_RenewLease_Handler,
+		},
+		{
+			MethodName: "ReportLost",
+			Handler:    _Master_ReportLost_Handler,
+		},
+		{
+			MethodName: "ReportWrite",
+			Handler:    _Master_ReportWrite_Handler,
+		},
+		{
+			MethodName: "Lookup",
+			Handler:    _Master_Lookup_Handler,
+		},
+		{
+			MethodName: "Stat",
+			Handler:    _Master_Stat_Handler,
+		},
+		{
+			MethodName: "FileChunks",
+			Handler:    _Master_FileChunks_Handler,
+		},
+		{
+			MethodName: "SetReplication",
+			Handler:    _Master_SetReplication_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "gfs.proto",
+}
+
+const (
+	ChunkServer_WriteChunk_FullMethodName   = "/gfs.rpc.ChunkServer/WriteChunk"
+	ChunkServer_ReadChunk_FullMethodName    = "/gfs.rpc.ChunkServer/ReadChunk"
+	ChunkServer_WritePrimary_FullMethodName = "/gfs.rpc.ChunkServer/WritePrimary"
+	ChunkServer_ApplyWrite_FullMethodName   = "/gfs.rpc.ChunkServer/ApplyWrite"
+	ChunkServer_Commit_FullMethodName       = "/gfs.rpc.ChunkServer/Commit"
+	ChunkServer_CopyChunk_FullMethodName    = "/gfs.rpc.ChunkServer/CopyChunk"
+	ChunkServer_DeleteChunk_FullMethodName  = "/gfs.rpc.ChunkServer/DeleteChunk"
+	ChunkServer_ChunkReport_FullMethodName  = "/gfs.rpc.ChunkServer/ChunkReport"
+)
+
+// ChunkServerClient is the client API for ChunkServer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ChunkServer is the data plane. Chunk bytes are streamed as a sequence of
+// ChunkData messages; metadata is read from the first message only.
+type ChunkServerClient interface {
+	WriteChunk(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ChunkData, WriteResult], error)
+	ReadChunk(ctx context.Context, in *ReadChunkRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChunkData], error)
+	WritePrimary(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ChunkData, WriteResult], error)
+	ApplyWrite(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ChunkData, WriteResult], error)
+	Commit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*WriteResult, error)
+	CopyChunk(ctx context.Context, in *CopyChunkRequest, opts ...grpc.CallOption) (*WriteResult, error)
+	DeleteChunk(ctx context.Context, in *DeleteChunkRequest, opts ...grpc.CallOption) (*WriteResult, error)
+	ChunkReport(ctx context.Context, in *ChunkReportRequest, opts ...grpc.CallOption) (*ChunkReportResponse, error)
+}
+
+type chunkServerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChunkServerClient(cc grpc.ClientConnInterface) ChunkServerClient {
+	return &chunkServerClient{cc}
+}
+
+func (c *chunkServerClient) WriteChunk(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ChunkData, WriteResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChunkServer_ServiceDesc.Streams[0], ChunkServer_WriteChunk_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ChunkData, WriteResult]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChunkServer_WriteChunkClient = grpc.ClientStreamingClient[ChunkData, WriteResult]
+
+func (c *chunkServerClient) ReadChunk(ctx context.Context, in *ReadChunkRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChunkData], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChunkServer_ServiceDesc.Streams[1], ChunkServer_ReadChunk_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ReadChunkRequest, ChunkData]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChunkServer_ReadChunkClient = grpc.ServerStreamingClient[ChunkData]
+
+func (c *chunkServerClient) WritePrimary(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ChunkData, WriteResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChunkServer_ServiceDesc.Streams[2], ChunkServer_WritePrimary_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ChunkData, WriteResult]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChunkServer_WritePrimaryClient = grpc.ClientStreamingClient[ChunkData, WriteResult]
+
+func (c *chunkServerClient) ApplyWrite(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ChunkData, WriteResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChunkServer_ServiceDesc.Streams[3], ChunkServer_ApplyWrite_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ChunkData, WriteResult]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChunkServer_ApplyWriteClient = grpc.ClientStreamingClient[ChunkData, WriteResult]
+
+func (c *chunkServerClient) Commit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*WriteResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WriteResult)
+	err := c.cc.Invoke(ctx, ChunkServer_Commit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkServerClient) CopyChunk(ctx context.Context, in *CopyChunkRequest, opts ...grpc.CallOption) (*WriteResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WriteResult)
+	err := c.cc.Invoke(ctx, ChunkServer_CopyChunk_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkServerClient) DeleteChunk(ctx context.Context, in *DeleteChunkRequest, opts ...grpc.CallOption) (*WriteResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WriteResult)
+	err := c.cc.Invoke(ctx, ChunkServer_DeleteChunk_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkServerClient) ChunkReport(ctx context.Context, in *ChunkReportRequest, opts ...grpc.CallOption) (*ChunkReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChunkReportResponse)
+	err := c.cc.Invoke(ctx, ChunkServer_ChunkReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChunkServerServer is the server API for ChunkServer service.
+// All implementations must embed UnimplementedChunkServerServer
+// for forward compatibility.
+//
+// ChunkServer is the data plane. Chunk bytes are streamed as a sequence of
+// ChunkData messages; metadata is read from the first message only.
+type ChunkServerServer interface {
+	WriteChunk(grpc.ClientStreamingServer[ChunkData, WriteResult]) error
+	ReadChunk(*ReadChunkRequest, grpc.ServerStreamingServer[ChunkData]) error
+	WritePrimary(grpc.ClientStreamingServer[ChunkData, WriteResult]) error
+	ApplyWrite(grpc.ClientStreamingServer[ChunkData, WriteResult]) error
+	Commit(context.Context, *CommitRequest) (*WriteResult, error)
+	CopyChunk(context.Context, *CopyChunkRequest) (*WriteResult, error)
+	DeleteChunk(context.Context, *DeleteChunkRequest) (*WriteResult, error)
+	ChunkReport(context.Context, *ChunkReportRequest) (*ChunkReportResponse, error)
+	mustEmbedUnimplementedChunkServerServer()
+}
+
+// UnimplementedChunkServerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedChunkServerServer struct{}
+
+func (UnimplementedChunkServerServer) WriteChunk(grpc.ClientStreamingServer[ChunkData, WriteResult]) error {
+	return status.Errorf(codes.Unimplemented, "method WriteChunk not implemented")
+}
+func (UnimplementedChunkServerServer) ReadChunk(*ReadChunkRequest, grpc.ServerStreamingServer[ChunkData]) error {
+	return status.Errorf(codes.Unimplemented, "method ReadChunk not implemented")
+}
+func (UnimplementedChunkServerServer) WritePrimary(grpc.ClientStreamingServer[ChunkData, WriteResult]) error {
+	return status.Errorf(codes.Unimplemented, "method WritePrimary not implemented")
+}
+func (UnimplementedChunkServerServer) ApplyWrite(grpc.ClientStreamingServer[ChunkData, WriteResult]) error {
+	return status.Errorf(codes.Unimplemented, "method ApplyWrite not implemented")
+}
+func (UnimplementedChunkServerServer) Commit(context.Context, *CommitRequest) (*WriteResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Commit not implemented")
+}
+func (UnimplementedChunkServerServer) CopyChunk(context.Context, *CopyChunkRequest) (*WriteResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CopyChunk not implemented")
+}
+func (UnimplementedChunkServerServer) DeleteChunk(context.Context, *DeleteChunkRequest) (*WriteResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteChunk not implemented")
+}
+func (UnimplementedChunkServerServer) ChunkReport(context.Context, *ChunkReportRequest) (*ChunkReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChunkReport not implemented")
+}
+func (UnimplementedChunkServerServer) mustEmbedUnimplementedChunkServerServer() {}
+func (UnimplementedChunkServerServer) testEmbeddedByValue()                     {}
+
+// UnsafeChunkServerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChunkServerServer will
+// result in compilation errors.
+type UnsafeChunkServerServer interface {
+	mustEmbedUnimplementedChunkServerServer()
+}
+
+func RegisterChunkServerServer(s grpc.ServiceRegistrar, srv ChunkServerServer) {
+	// If the following call pancis, it indicates UnimplementedChunkServerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ChunkServer_ServiceDesc, srv)
+}
+
+func _ChunkServer_WriteChunk_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChunkServerServer).WriteChunk(&grpc.GenericServerStream[ChunkData, WriteResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChunkServer_WriteChunkServer = grpc.ClientStreamingServer[ChunkData, WriteResult]
+
+func _ChunkServer_ReadChunk_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadChunkRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChunkServerServer).ReadChunk(m, &grpc.GenericServerStream[ReadChunkRequest, ChunkData]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChunkServer_ReadChunkServer = grpc.ServerStreamingServer[ChunkData]
+
+func _ChunkServer_WritePrimary_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChunkServerServer).WritePrimary(&grpc.GenericServerStream[ChunkData, WriteResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChunkServer_WritePrimaryServer = grpc.ClientStreamingServer[ChunkData, WriteResult]
+
+func _ChunkServer_ApplyWrite_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChunkServerServer).ApplyWrite(&grpc.GenericServerStream[ChunkData, WriteResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChunkServer_ApplyWriteServer = grpc.ClientStreamingServer[ChunkData, WriteResult]
+
+func _ChunkServer_Commit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChunkServerServer).Commit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChunkServer_Commit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChunkServerServer).Commit(ctx, req.(*CommitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChunkServer_CopyChunk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CopyChunkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChunkServerServer).CopyChunk(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChunkServer_CopyChunk_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChunkServerServer).CopyChunk(ctx, req.(*CopyChunkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChunkServer_DeleteChunk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteChunkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChunkServerServer).DeleteChunk(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChunkServer_DeleteChunk_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChunkServerServer).DeleteChunk(ctx, req.(*DeleteChunkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChunkServer_ChunkReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChunkReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChunkServerServer).ChunkReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChunkServer_ChunkReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChunkServerServer).ChunkReport(ctx, req.(*ChunkReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ChunkServer_ServiceDesc is the grpc.ServiceDesc for ChunkServer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChunkServer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gfs.rpc.ChunkServer",
+	HandlerType: (*ChunkServerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Commit",
+			Handler:    _ChunkServer_Commit_Handler,
+		},
+		{
+			MethodName: "CopyChunk",
+			Handler:    _ChunkServer_CopyChunk_Handler,
+		},
+		{
+			MethodName: "DeleteChunk",
+			Handler:    _ChunkServer_DeleteChunk_Handler,
+		},
+		{
+			MethodName: "ChunkReport",
+			Handler:    _ChunkServer_ChunkReport_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WriteChunk",
+			Handler:       _ChunkServer_WriteChunk_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ReadChunk",
+			Handler:       _ChunkServer_ReadChunk_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WritePrimary",
+			Handler:       _ChunkServer_WritePrimary_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ApplyWrite",
+			Handler:       _ChunkServer_ApplyWrite_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "gfs.proto",
+}
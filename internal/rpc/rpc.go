@@ -0,0 +1,78 @@
+// Package rpc holds the gRPC master and chunkserver APIs generated from
+// gfs.proto, plus helpers for streaming chunk data over them. The gRPC
+// servers run next to the JSON HTTP endpoints while callers migrate.
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative gfs.proto
+
+import (
+	"errors"
+	"io"
+)
+
+// StreamBlockSize is how many chunk bytes go in one ChunkData message.
+const StreamBlockSize = 64 * 1024
+
+// SendChunk streams r as ChunkData messages. meta carries the chunk's metadata
+// and is sent with the first block; an empty r still sends meta once.
+func SendChunk(send func(*ChunkData) error, meta *ChunkData, r io.Reader) error {
+	buf := make([]byte, StreamBlockSize)
+	first := true
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 || first {
+			msg := &ChunkData{Data: buf[:n]}
+			if first {
+				msg = &ChunkData{ChunkId: meta.ChunkId, Seq: meta.Seq, Version: meta.Version, ReqId: meta.ReqId, Data: buf[:n]}
+				first = false
+			}
+			if serr := send(msg); serr != nil {
+				return serr
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ChunkReader reads the data of a ChunkData stream. The first message is
+// received up front so callers can inspect its metadata before reading.
+type ChunkReader struct {
+	recv func() (*ChunkData, error)
+	meta *ChunkData
+	buf  []byte
+}
+
+// NewChunkReader receives the first message of a stream.
+func NewChunkReader(recv func() (*ChunkData, error)) (*ChunkReader, error) {
+	first, err := recv()
+	if err == io.EOF {
+		return nil, errors.New("empty chunk stream")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ChunkReader{recv: recv, meta: first, buf: first.Data}, nil
+}
+
+// Meta returns the first message of the stream.
+func (c *ChunkReader) Meta() *ChunkData {
+	return c.meta
+}
+
+func (c *ChunkReader) Read(p []byte) (int, error) {
+	for len(c.buf) == 0 {
+		msg, err := c.recv()
+		if err != nil {
+			return 0, err
+		}
+		c.buf = msg.Data
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
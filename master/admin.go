@@ -0,0 +1,443 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Read-only views for operators, and the checkpoint and fsck actions; see
+// cmd/gfsadmin. Unlike /cluster_info they summarize instead of dumping every
+// chunk.
+
+type clusterStatus struct {
+	Nodes struct {
+		Total       int `json:"total"`
+		Alive       int `json:"alive"`
+		Dead        int `json:"dead"`
+		Draining    int `json:"draining"`
+		Maintenance int `json:"maintenance"`
+	} `json:"nodes"`
+	Capacity struct {
+		Total uint64 `json:"total_bytes"`
+		Used  uint64 `json:"used_bytes"`
+		Free  uint64 `json:"free_bytes"`
+	} `json:"capacity"` // of alive nodes
+	Files           int    `json:"files"`
+	Dirs            int    `json:"dirs"` // made by mkdir or implied by a file under them
+	Chunks          int    `json:"chunks"`
+	Bytes           int64  `json:"bytes"` // committed file bytes, before replication
+	UnderReplicated int    `json:"under_replicated"`
+	Lost            int    `json:"lost"` // chunks with no readable replica
+	Leases          int    `json:"leases"`
+	RepairPending   int    `json:"repair_pending"`
+	RepairRunning   int    `json:"repair_running"`
+	RepairFailed    int    `json:"repair_failed"`
+	Rebalance       string `json:"rebalance"`
+}
+
+// /status : one-screen cluster summary
+func statusHandler(w http.ResponseWriter, r *http.Request) {
+	rq := repairs.status(0)
+	st := clusterStatus{
+		RepairPending: rq.Pending,
+		RepairRunning: rq.Running,
+		RepairFailed:  rq.Failed,
+		Rebalance:     rebalance.status().State,
+	}
+
+	now := time.Now()
+	mu.Lock()
+	for _, cs := range chunkServers {
+		st.Nodes.Total++
+		switch {
+		case cs.Alive:
+			st.Nodes.Alive++
+			st.Capacity.Total += cs.TotalBytes
+			st.Capacity.Used += cs.UsedBytes
+			st.Capacity.Free += cs.FreeBytes
+		default:
+			st.Nodes.Dead++
+		}
+		if cs.Draining {
+			st.Nodes.Draining++
+		}
+		if cs.inMaintenance(now) {
+			st.Nodes.Maintenance++
+		}
+	}
+	st.Files, st.Chunks = len(files), len(chunks)
+	allDirs := make(map[string]bool, len(dirs))
+	for d := range dirs {
+		allDirs[d] = true
+	}
+	for name, fm := range files {
+		st.Bytes += fm.Length
+		for i := strings.LastIndexByte(name, '/'); i > 0; i = strings.LastIndexByte(name[:i], '/') {
+			allDirs[name[:i]] = true
+		}
+	}
+	st.Dirs = len(allDirs)
+	for _, cm := range chunks {
+		if readableReplicaCount(cm) == 0 {
+			st.Lost++
+		} else if aliveReplicaCount(cm) < chunkReplication(cm) {
+			st.UnderReplicated++
+		}
+		if cm.LeaseValid() {
+			st.Leases++
+		}
+	}
+	mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
+
+// chunkHealth is a chunk short of its replica target.
+type chunkHealth struct {
+	ChunkID  string   `json:"chunk_id"`
+	File     string   `json:"file"`
+	Index    int      `json:"index"`
+	Live     int      `json:"live"`
+	Want     int      `json:"want"`
+	Replicas []string `json:"replicas"`
+	Queued   bool     `json:"queued"` // waiting in or running from the repair queue
+}
+
+// /under_replicated : chunks below their target, fewest live replicas
+// first. Chunks with no live replica at all have live 0.
+func underReplicatedHandler(w http.ResponseWriter, r *http.Request) {
+	queued := repairs.queued()
+
+	mu.Lock()
+	out := []chunkHealth{}
+	for cid, cm := range chunks {
+		live, want := aliveReplicaCount(cm), chunkReplication(cm)
+		if live >= want {
+			continue
+		}
+		out = append(out, chunkHealth{
+			ChunkID:  cid,
+			File:     cm.FileName,
+			Index:    cm.Index,
+			Live:     live,
+			Want:     want,
+			Replicas: append([]string{}, cm.Replicas...),
+			Queued:   queued[cid],
+		})
+	}
+	mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Live != b.Live {
+			return a.Live < b.Live
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Index < b.Index
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+type leaseInfo struct {
+	ChunkID     string `json:"chunk_id"`
+	File        string `json:"file"`
+	Index       int    `json:"index"`
+	Primary     string `json:"primary"`
+	ExpiresUnix int64  `json:"expires_unix"`
+	Version     uint64 `json:"version"`
+}
+
+// /leases?node=host:port : chunks with a valid lease, optionally only those
+// held by one node
+func leasesHandler(w http.ResponseWriter, r *http.Request) {
+	node := r.URL.Query().Get("node")
+
+	mu.Lock()
+	out := []leaseInfo{}
+	for cid, cm := range chunks {
+		if !cm.LeaseValid() || (node != "" && cm.Primary != node) {
+			continue
+		}
+		out = append(out, leaseInfo{
+			ChunkID:     cid,
+			File:        cm.FileName,
+			Index:       cm.Index,
+			Primary:     cm.Primary,
+			ExpiresUnix: cm.LeaseExpires,
+			Version:     cm.Version,
+		})
+	}
+	mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].File != out[j].File {
+			return out[i].File < out[j].File
+		}
+		return out[i].Index < out[j].Index
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+// /checkpoint : write a checkpoint now rather than at the next sweep
+func checkpointHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := writeCheckpoint(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	mu.Lock()
+	resp := map[string]any{"files": len(files), "chunks": len(chunks), "saved_unix": time.Now().Unix()}
+	mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+type fsckRequest struct {
+	Path   string `json:"path"`
+	Repair bool   `json:"repair,omitempty"`
+}
+
+// fsckProblem is one inconsistency. Kind is one of the fsck* constants.
+type fsckProblem struct {
+	Kind    string `json:"kind"`
+	File    string `json:"file,omitempty"`
+	ChunkID string `json:"chunk_id,omitempty"`
+	Node    string `json:"node,omitempty"`
+	Detail  string `json:"detail"`
+}
+
+const (
+	fsckMissingMeta     = "missing_metadata" // a file lists a chunk the master has no record of
+	fsckMisfiled        = "misfiled"         // a chunk's record names another file or index
+	fsckLength          = "length"           // file length isn't the sum of its chunk lengths
+	fsckLost            = "lost"             // no readable replica
+	fsckUnderReplicated = "under_replicated" // fewer live replicas than the target
+	fsckOverReplicated  = "over_replicated"  // more live replicas than the target
+	fsckSingleRack      = "single_rack"      // every replica in one rack while others exist
+	fsckMissingReplica  = "missing_replica"  // a node doesn't have a chunk it is listed for
+	fsckUnlisted        = "unlisted_replica" // a node holds a chunk it isn't listed for
+	fsckOrphan          = "orphan"           // a node holds a chunk no file refers to
+	fsckUnreachable     = "unreachable"      // a node's chunk report couldn't be fetched
+)
+
+type fsckReport struct {
+	Path       string         `json:"path"`
+	Files      int            `json:"files"`
+	Chunks     int            `json:"chunks"`
+	Nodes      int            `json:"nodes"` // chunk reports compared, root only
+	Healthy    bool           `json:"healthy"`
+	Counts     map[string]int `json:"counts"`
+	Problems   []fsckProblem  `json:"problems"`
+	Queued     int            `json:"queued,omitempty"`     // repairs queued by repair
+	Trimmed    int            `json:"trimmed,omitempty"`    // over-replicated chunks handed to ensureReplication
+	Reconciled []string       `json:"reconciled,omitempty"` // nodes reconciled by repair
+	Seconds    float64        `json:"seconds"`
+}
+
+// /fsck : check the metadata of every file under path and, for the whole
+// namespace, compare each alive node's chunk report with the replica lists.
+// With repair set, short chunks are queued for repair, surplus replicas
+// trimmed and nodes whose reports disagree reconciled; orphans are only
+// reported.
+func fsckHandler(w http.ResponseWriter, r *http.Request) {
+	var req fsckRequest
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+	// the body is optional; an empty one checks everything
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	report, err := runFsck(cleanPath(req.Path), req.Repair)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func runFsck(p string, repair bool) (*fsckReport, error) {
+	start := time.Now()
+	report := &fsckReport{Path: p, Counts: make(map[string]int), Problems: []fsckProblem{}}
+	add := func(pr fsckProblem) {
+		report.Problems = append(report.Problems, pr)
+		report.Counts[pr.Kind]++
+	}
+
+	// chunk reports are fetched without mu; chunks written since are only
+	// compared if the master knew them when the report was taken
+	var reports map[string]map[string]bool
+	if p == "" {
+		reports = fetchReports(add)
+		report.Nodes = len(reports)
+	}
+
+	var under, over []string
+	disagree := make(map[string]bool)
+
+	mu.Lock()
+	if _, ok := files[p]; !ok && !isDirLocked(p) {
+		mu.Unlock()
+		return nil, errorf(http.StatusNotFound, "%s: no such file or directory", p)
+	}
+	racks := make(map[string]bool)
+	for _, cs := range chunkServers {
+		if cs.Alive && cs.Rack != "" {
+			racks[cs.Rack] = true
+		}
+	}
+	prefix := dirPrefix(p)
+	for name, fm := range files {
+		if name != p && !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		report.Files++
+		var length int64
+		for i, cid := range fm.Chunks {
+			report.Chunks++
+			cm, ok := chunks[cid]
+			if !ok {
+				add(fsckProblem{Kind: fsckMissingMeta, File: name, ChunkID: cid, Detail: fmt.Sprintf("chunk %d has no metadata", i)})
+				continue
+			}
+			length += cm.Length
+			if cm.FileName != name || cm.Index != i {
+				add(fsckProblem{Kind: fsckMisfiled, File: name, ChunkID: cid,
+					Detail: fmt.Sprintf("listed as chunk %d, recorded as %s chunk %d", i, cm.FileName, cm.Index)})
+			}
+			live, want := aliveReplicaCount(cm), chunkReplication(cm)
+			switch {
+			case readableReplicaCount(cm) == 0:
+				add(fsckProblem{Kind: fsckLost, File: name, ChunkID: cid, Detail: fmt.Sprintf("no readable replica of %d", want)})
+			case live < want:
+				add(fsckProblem{Kind: fsckUnderReplicated, File: name, ChunkID: cid, Detail: fmt.Sprintf("%d of %d replicas", live, want)})
+				under = append(under, cid)
+			case live > want:
+				add(fsckProblem{Kind: fsckOverReplicated, File: name, ChunkID: cid, Detail: fmt.Sprintf("%d replicas, target %d", live, want)})
+				over = append(over, cid)
+			}
+			if cm.SingleDomain && len(racks) > 1 {
+				add(fsckProblem{Kind: fsckSingleRack, File: name, ChunkID: cid, Detail: "all replicas share one rack"})
+			}
+			if reports == nil || cm.Length == 0 {
+				// never written, so no node holds it yet
+				continue
+			}
+			for _, node := range cm.Replicas {
+				if rep, ok := reports[node]; ok && !rep[cid] {
+					add(fsckProblem{Kind: fsckMissingReplica, File: name, ChunkID: cid, Node: node, Detail: "listed as a replica but not held"})
+					disagree[node] = true
+				}
+			}
+		}
+		if length != fm.Length {
+			add(fsckProblem{Kind: fsckLength, File: name, Detail: fmt.Sprintf("length %d, chunks hold %d", fm.Length, length)})
+		}
+	}
+	if p == "" {
+		for cid, cm := range chunks {
+			if fm, ok := files[cm.FileName]; !ok || !slices.Contains(fm.Chunks, cid) {
+				add(fsckProblem{Kind: fsckMisfiled, File: cm.FileName, ChunkID: cid, Detail: "recorded under a file that doesn't list it"})
+			}
+		}
+	}
+	for node, held := range reports {
+		for cid := range held {
+			cm, ok := chunks[cid]
+			switch {
+			case !ok:
+				add(fsckProblem{Kind: fsckOrphan, ChunkID: cid, Node: node, Detail: "held but not in any file"})
+			case !cm.replicaSet()[node]:
+				add(fsckProblem{Kind: fsckUnlisted, File: cm.FileName, ChunkID: cid, Node: node, Detail: "held but not listed as a replica"})
+				disagree[node] = true
+			}
+		}
+	}
+	mu.Unlock()
+
+	sort.SliceStable(report.Problems, func(i, j int) bool {
+		a, b := report.Problems[i], report.Problems[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.ChunkID < b.ChunkID
+	})
+	report.Healthy = len(report.Problems) == 0
+
+	if repair {
+		for _, cid := range under {
+			repairs.enqueue(cid, "")
+		}
+		report.Queued = len(under)
+		for _, cid := range over {
+			if !rebalance.isMoving(cid) {
+				go ensureReplication(cid)
+				report.Trimmed++
+			}
+		}
+		for node := range disagree {
+			go reconcileNode(node, "fsck")
+			report.Reconciled = append(report.Reconciled, node)
+		}
+		sort.Strings(report.Reconciled)
+	}
+	report.Seconds = time.Since(start).Seconds()
+	return report, nil
+}
+
+// fetchReports gets the chunk report of every alive node at once, as sets
+// of chunk IDs. Nodes that don't answer are reported through add.
+func fetchReports(add func(fsckProblem)) map[string]map[string]bool {
+	mu.Lock()
+	var nodes []string
+	for id, cs := range chunkServers {
+		if cs.Alive {
+			nodes = append(nodes, id)
+		}
+	}
+	mu.Unlock()
+
+	out := make(map[string]map[string]bool, len(nodes))
+	var wg sync.WaitGroup
+	var outMu sync.Mutex
+	for _, id := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report, err := fetchChunkReport(id)
+			outMu.Lock()
+			defer outMu.Unlock()
+			if err != nil {
+				add(fsckProblem{Kind: fsckUnreachable, Node: id, Detail: err.Error()})
+				return
+			}
+			held := make(map[string]bool, len(report.Chunks))
+			for _, cid := range report.Chunks {
+				held[cid] = true
+			}
+			out[id] = held
+		}()
+	}
+	wg.Wait()
+	return out
+}
@@ -10,9 +10,12 @@ type Checkpoint struct {
 	Files        map[string]*FileMeta        `json:"files"`
 	Chunks       map[string]*ChunkMeta       `json:"chunks"`
 	ChunkServers map[string]*ChunkServerInfo `json:"chunk_servers"`
+	Dirs         map[string]bool             `json:"dirs,omitempty"`
 }
 
-func writeCheckpoint() {
+// writeCheckpoint saves the metadata and starts a new op-log. Errors are
+// logged as well as returned.
+func writeCheckpoint() error {
 	mu.Lock()
 	defer mu.Unlock()
 
@@ -20,19 +23,31 @@ func writeCheckpoint() {
 		Files:        files,
 		Chunks:       chunks,
 		ChunkServers: chunkServers,
+		Dirs:         dirs,
 	}
 
 	b, err := json.MarshalIndent(cp, "", "  ")
 	if err != nil {
 		log.Printf("master: checkpoint marshal error: %v", err)
-		return
+		return err
 	}
 
-	err = os.WriteFile("checkpoint.json", b, 0644)
-	if err != nil {
+	// write and rename so a crash never leaves a torn checkpoint, then start
+	// a new op-log: replaying entries the checkpoint already holds would
+	// re-apply deletes and renames to files created since
+	tmp := checkpointPath + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		log.Printf("master: checkpoint write error: %v", err)
+		return err
+	}
+	if err := os.Rename(tmp, checkpointPath); err != nil {
 		log.Printf("master: checkpoint write error: %v", err)
-		return
+		return err
+	}
+	if err := os.Truncate(opLogPath, 0); err != nil && !os.IsNotExist(err) {
+		log.Printf("master: op-log truncate error: %v", err)
 	}
 
 	log.Printf("master: checkpoint saved (%d files, %d chunks)", len(files), len(chunks))
+	return nil
 }
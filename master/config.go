@@ -0,0 +1,295 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Config holds every master tunable. Values are resolved in order:
+// built-in defaults, then the optional JSON config file, then GFS_MASTER_*
+// environment variables, then command-line flags.
+type Config struct {
+	ListenAddr        string   `json:"listen_addr"`
+	GRPCListenAddr    string   `json:"grpc_listen_addr"` // empty disables the gRPC API
+	DataDir           string   `json:"data_dir"`
+	CheckpointPath    string   `json:"checkpoint_path"`
+	OpLogPath         string   `json:"oplog_path"`
+	HeartbeatTimeout  Duration `json:"heartbeat_timeout"`
+	SweepInterval     Duration `json:"sweep_interval"`
+	ReplicationFactor int      `json:"replication_factor"`
+	ChunkSize         int64    `json:"chunk_size"`
+	LeaseDuration     Duration `json:"lease_duration"`
+	RepairConcurrency int      `json:"repair_concurrency"`  // repairs running at once, cluster-wide
+	RepairPerNode     int      `json:"repair_per_node"`     // repairs a node takes part in at once
+	RepairMaxAttempts int      `json:"repair_max_attempts"` // attempts before a repair is dropped
+	ScanInterval      Duration `json:"scan_interval"`       // between replication scans
+	// RebalanceThreshold is how far, as a fraction of capacity, a node's
+	// utilization may stray from the cluster average before the rebalancer
+	// moves replicas off or onto it.
+	RebalanceThreshold float64 `json:"rebalance_threshold"`
+	RebalanceBandwidth int64   `json:"rebalance_bandwidth"` // bytes/s the rebalancer may copy
+}
+
+// Duration is a time.Duration that reads and writes as "10s" style strings in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		*d = Duration(v)
+		return nil
+	}
+	// plain numbers are taken as seconds
+	var secs float64
+	if err := json.Unmarshal(b, &secs); err != nil {
+		return fmt.Errorf("invalid duration %s", string(b))
+	}
+	*d = Duration(secs * float64(time.Second))
+	return nil
+}
+
+func defaultConfig() Config {
+	return Config{
+		ListenAddr:        ":8080",
+		GRPCListenAddr:    ":9080",
+		DataDir:           ".",
+		CheckpointPath:    "checkpoint.json",
+		OpLogPath:         "oplog.jsonl",
+		HeartbeatTimeout:  Duration(10 * time.Second),
+		SweepInterval:     Duration(3 * time.Second),
+		ReplicationFactor: 2,
+		ChunkSize:         4 * 1024 * 1024,
+		LeaseDuration:     Duration(10 * time.Second),
+		RepairConcurrency: 16,
+		RepairPerNode:     2,
+		RepairMaxAttempts: 5,
+		ScanInterval:      Duration(30 * time.Second),
+
+		RebalanceThreshold: 0.1,
+		RebalanceBandwidth: 16 * 1024 * 1024,
+	}
+}
+
+// runtime tunables, filled from Config by applyConfig
+var (
+	cfg               = defaultConfig()
+	heartbeatTimeout  time.Duration
+	sweepInterval     time.Duration
+	scanInterval      time.Duration
+	replicationFactor int
+	ChunkSize         int64
+	leaseSeconds      int64
+	checkpointPath    string
+	opLogPath         string
+)
+
+func init() {
+	applyConfig(cfg)
+}
+
+// loadConfig resolves the master configuration from args and the process environment.
+func loadConfig(args []string) (Config, error) {
+	c := defaultConfig()
+
+	fs := flag.NewFlagSet("master", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv("GFS_MASTER_CONFIG"), "path to JSON config file")
+	listen := fs.String("listen", c.ListenAddr, "HTTP listen address")
+	grpcListen := fs.String("grpc-listen", c.GRPCListenAddr, "gRPC listen address, empty to disable")
+	dataDir := fs.String("data-dir", c.DataDir, "directory for checkpoint and op-log")
+	checkpoint := fs.String("checkpoint", c.CheckpointPath, "checkpoint file (relative to data-dir)")
+	oplog := fs.String("oplog", c.OpLogPath, "op-log file (relative to data-dir)")
+	hbTimeout := fs.Duration("heartbeat-timeout", time.Duration(c.HeartbeatTimeout), "mark chunkserver dead after this long without heartbeat")
+	sweep := fs.Duration("sweep-interval", time.Duration(c.SweepInterval), "interval between sweeper passes")
+	repl := fs.Int("replication", c.ReplicationFactor, "replicas per chunk")
+	chunkSize := fs.Int64("chunk-size", c.ChunkSize, "chunk size in bytes")
+	lease := fs.Duration("lease", time.Duration(c.LeaseDuration), "primary lease length")
+	repairConc := fs.Int("repair-concurrency", c.RepairConcurrency, "repairs running at once, cluster-wide")
+	repairPerNode := fs.Int("repair-per-node", c.RepairPerNode, "repairs a single node takes part in at once")
+	scan := fs.Duration("scan-interval", time.Duration(c.ScanInterval), "interval between replication scans")
+	repairAttempts := fs.Int("repair-max-attempts", c.RepairMaxAttempts, "attempts before a repair is dropped")
+	rebalThreshold := fs.Float64("rebalance-threshold", c.RebalanceThreshold, "utilization spread from the average the rebalancer tolerates")
+	rebalBandwidth := fs.Int64("rebalance-bandwidth", c.RebalanceBandwidth, "bytes per second the rebalancer may copy")
+	if err := fs.Parse(args); err != nil {
+		return c, err
+	}
+
+	if *configPath != "" {
+		b, err := os.ReadFile(*configPath)
+		if err != nil {
+			return c, fmt.Errorf("read config: %w", err)
+		}
+		if err := json.Unmarshal(b, &c); err != nil {
+			return c, fmt.Errorf("parse config %s: %w", *configPath, err)
+		}
+	}
+
+	if err := applyEnv(&c); err != nil {
+		return c, err
+	}
+
+	// only flags given explicitly override file and env values
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "listen":
+			c.ListenAddr = *listen
+		case "grpc-listen":
+			c.GRPCListenAddr = *grpcListen
+		case "data-dir":
+			c.DataDir = *dataDir
+		case "checkpoint":
+			c.CheckpointPath = *checkpoint
+		case "oplog":
+			c.OpLogPath = *oplog
+		case "heartbeat-timeout":
+			c.HeartbeatTimeout = Duration(*hbTimeout)
+		case "sweep-interval":
+			c.SweepInterval = Duration(*sweep)
+		case "replication":
+			c.ReplicationFactor = *repl
+		case "chunk-size":
+			c.ChunkSize = *chunkSize
+		case "lease":
+			c.LeaseDuration = Duration(*lease)
+		case "repair-concurrency":
+			c.RepairConcurrency = *repairConc
+		case "repair-per-node":
+			c.RepairPerNode = *repairPerNode
+		case "repair-max-attempts":
+			c.RepairMaxAttempts = *repairAttempts
+		case "scan-interval":
+			c.ScanInterval = Duration(*scan)
+		case "rebalance-threshold":
+			c.RebalanceThreshold = *rebalThreshold
+		case "rebalance-bandwidth":
+			c.RebalanceBandwidth = *rebalBandwidth
+		}
+	})
+
+	return c, c.validate()
+}
+
+func applyEnv(c *Config) error {
+	str := func(key string, dst *string) {
+		if v, ok := os.LookupEnv(key); ok {
+			*dst = v
+		}
+	}
+	dur := func(key string, dst *Duration) error {
+		if v, ok := os.LookupEnv(key); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			*dst = Duration(d)
+		}
+		return nil
+	}
+
+	str("GFS_MASTER_LISTEN", &c.ListenAddr)
+	str("GFS_MASTER_GRPC_LISTEN", &c.GRPCListenAddr)
+	str("GFS_MASTER_DATA_DIR", &c.DataDir)
+	str("GFS_MASTER_CHECKPOINT", &c.CheckpointPath)
+	str("GFS_MASTER_OPLOG", &c.OpLogPath)
+	if err := dur("GFS_MASTER_HEARTBEAT_TIMEOUT", &c.HeartbeatTimeout); err != nil {
+		return err
+	}
+	if err := dur("GFS_MASTER_SWEEP_INTERVAL", &c.SweepInterval); err != nil {
+		return err
+	}
+	if err := dur("GFS_MASTER_LEASE", &c.LeaseDuration); err != nil {
+		return err
+	}
+	if err := dur("GFS_MASTER_SCAN_INTERVAL", &c.ScanInterval); err != nil {
+		return err
+	}
+	for key, dst := range map[string]*int{
+		"GFS_MASTER_REPLICATION":         &c.ReplicationFactor,
+		"GFS_MASTER_REPAIR_CONCURRENCY":  &c.RepairConcurrency,
+		"GFS_MASTER_REPAIR_PER_NODE":     &c.RepairPerNode,
+		"GFS_MASTER_REPAIR_MAX_ATTEMPTS": &c.RepairMaxAttempts,
+	} {
+		if v, ok := os.LookupEnv(key); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			*dst = n
+		}
+	}
+	for key, dst := range map[string]*int64{
+		"GFS_MASTER_CHUNK_SIZE":          &c.ChunkSize,
+		"GFS_MASTER_REBALANCE_BANDWIDTH": &c.RebalanceBandwidth,
+	} {
+		if v, ok := os.LookupEnv(key); ok {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			*dst = n
+		}
+	}
+	if v, ok := os.LookupEnv("GFS_MASTER_REBALANCE_THRESHOLD"); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("GFS_MASTER_REBALANCE_THRESHOLD: %w", err)
+		}
+		c.RebalanceThreshold = f
+	}
+	return nil
+}
+
+func (c Config) validate() error {
+	if c.ReplicationFactor < 1 {
+		return fmt.Errorf("replication factor must be >= 1, got %d", c.ReplicationFactor)
+	}
+	if c.RepairConcurrency < 1 || c.RepairPerNode < 1 || c.RepairMaxAttempts < 1 {
+		return fmt.Errorf("repair concurrency, per-node limit and max attempts must be >= 1")
+	}
+	if c.ChunkSize <= 0 {
+		return fmt.Errorf("chunk size must be positive, got %d", c.ChunkSize)
+	}
+	if c.RebalanceThreshold <= 0 || c.RebalanceThreshold >= 1 || c.RebalanceBandwidth <= 0 {
+		return fmt.Errorf("rebalance threshold must be in (0, 1) and bandwidth positive")
+	}
+	if c.HeartbeatTimeout <= 0 || c.SweepInterval <= 0 || c.ScanInterval <= 0 || c.LeaseDuration < Duration(time.Second) {
+		return fmt.Errorf("heartbeat timeout, sweep and scan intervals must be positive, lease at least 1s")
+	}
+	if time.Duration(c.LeaseDuration)%time.Second != 0 {
+		// leases are granted and renewed in whole seconds
+		return fmt.Errorf("lease must be a whole number of seconds, got %s", time.Duration(c.LeaseDuration))
+	}
+	return nil
+}
+
+// resolve returns p joined onto the data dir unless it is already absolute.
+func (c Config) resolve(p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(c.DataDir, p)
+}
+
+func applyConfig(c Config) {
+	cfg = c
+	heartbeatTimeout = time.Duration(c.HeartbeatTimeout)
+	sweepInterval = time.Duration(c.SweepInterval)
+	scanInterval = time.Duration(c.ScanInterval)
+	replicationFactor = c.ReplicationFactor
+	ChunkSize = c.ChunkSize
+	leaseSeconds = int64(time.Duration(c.LeaseDuration) / time.Second)
+	checkpointPath = c.resolve(c.CheckpointPath)
+	opLogPath = c.resolve(c.OpLogPath)
+}
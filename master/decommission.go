@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+)
+
+type decommissionRequest struct {
+	Node   string `json:"node"`
+	Cancel bool   `json:"cancel,omitempty"` // put the node back into service
+}
+
+// drainStatus says how far a decommission has come. A node is safe to shut
+// down once Pending is zero: every chunk it holds has its full replica count
+// on other nodes.
+type drainStatus struct {
+	Node     string   `json:"node"`
+	Draining bool     `json:"draining"`
+	Alive    bool     `json:"alive"`
+	Chunks   int      `json:"chunks"`  // chunks the node still holds
+	Pending  int      `json:"pending"` // of those, chunks short of their target elsewhere
+	Unique   []string `json:"unique"`  // chunks with no other live copy
+	Done     bool     `json:"done"`
+}
+
+// drainStatusOf reports on node. Callers must hold mu.
+func drainStatusOf(id string, cs *ChunkServerInfo) drainStatus {
+	st := drainStatus{Node: id, Draining: cs.Draining, Alive: cs.Alive, Unique: []string{}}
+	for cid, cm := range chunks {
+		if !cm.replicaSet()[id] {
+			continue
+		}
+		st.Chunks++
+		others := 0
+		for _, r := range cm.Replicas {
+			if o, ok := chunkServers[r]; ok && r != id && o.countsTowardTarget() {
+				others++
+			}
+		}
+		if others < chunkReplication(cm) {
+			st.Pending++
+		}
+		if others == 0 {
+			st.Unique = append(st.Unique, cid)
+		}
+	}
+	sort.Strings(st.Unique)
+	st.Done = cs.Draining && st.Pending == 0
+	return st
+}
+
+// checkDrains logs when a draining node stops holding anything that isn't
+// fully replicated elsewhere. Callers must hold mu.
+func checkDrains() {
+	for id, cs := range chunkServers {
+		if !cs.Draining {
+			cs.Drained = false
+			continue
+		}
+		done := drainStatusOf(id, cs).Done
+		if done && !cs.Drained {
+			log.Printf("master: chunkserver %s is drained and can be shut down", id)
+		}
+		cs.Drained = done
+	}
+}
+
+// drainNode queues a new copy of every chunk on id that is short of its
+// target without it.
+func drainNode(id string) {
+	mu.Lock()
+	var toRepair []string
+	for cid, cm := range chunks {
+		if cm.replicaSet()[id] && aliveReplicaCount(cm) < chunkReplication(cm) {
+			toRepair = append(toRepair, cid)
+		}
+	}
+	mu.Unlock()
+
+	for _, cid := range toRepair {
+		repairs.enqueue(cid, "")
+	}
+	log.Printf("master: queued %d repairs to drain %s", len(toRepair), id)
+}
+
+// /decommission : POST {"node": "host:port"} starts draining a node, with
+// "cancel": true it returns to service. GET ?node=host:port reports progress;
+// without node it lists every draining node.
+func decommissionHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		node := r.URL.Query().Get("node")
+		mu.Lock()
+		if node != "" {
+			cs, ok := chunkServers[node]
+			if !ok {
+				mu.Unlock()
+				http.Error(w, "chunkserver not found", http.StatusNotFound)
+				return
+			}
+			st := drainStatusOf(node, cs)
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(st)
+			return
+		}
+		out := []drainStatus{}
+		for id, cs := range chunkServers {
+			if cs.Draining {
+				out = append(out, drainStatusOf(id, cs))
+			}
+		}
+		mu.Unlock()
+		sort.Slice(out, func(i, j int) bool { return out[i].Node < out[j].Node })
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(out)
+		return
+
+	case http.MethodPost:
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	var req decommissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.Node == "" {
+		http.Error(w, "node required", http.StatusBadRequest)
+		return
+	}
+
+	mu.Lock()
+	cs, ok := chunkServers[req.Node]
+	if !ok {
+		mu.Unlock()
+		http.Error(w, "chunkserver not found", http.StatusNotFound)
+		return
+	}
+	changed := cs.Draining == req.Cancel
+	cs.Draining = !req.Cancel
+	if req.Cancel {
+		cs.Drained = false
+	}
+	st := drainStatusOf(req.Node, cs)
+	if changed {
+		appendOpLog("decommission", map[string]any{
+			"node":     req.Node,
+			"draining": !req.Cancel,
+		})
+	}
+	mu.Unlock()
+
+	if changed {
+		if req.Cancel {
+			log.Printf("master: decommission of %s cancelled", req.Node)
+			// its replicas count again; trim whatever was copied meanwhile
+			go scanReplication()
+		} else {
+			log.Printf("master: decommissioning %s (%d chunks)", req.Node, st.Chunks)
+			go drainNode(req.Node)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"gfs/internal/rpc"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcMaster serves the rpc.Master API on top of the same state and logic as
+// the HTTP handlers.
+type grpcMaster struct {
+	rpc.UnimplementedMasterServer
+}
+
+func (grpcMaster) Register(ctx context.Context, req *rpc.RegisterRequest) (*rpc.RegisterResponse, error) {
+	err := registerChunkServer(RegisterRequest{
+		Port:        req.Port,
+		Addr:        req.Addr,
+		UUID:        req.Uuid,
+		Incarnation: req.Incarnation,
+		Rack:        req.Rack,
+		GRPCAddr:    req.GrpcAddr,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpc.RegisterResponse{}, nil
+}
+
+func (grpcMaster) Heartbeat(ctx context.Context, req *rpc.HeartbeatRequest) (*rpc.HeartbeatResponse, error) {
+	hb := HeartbeatRequest{
+		Port:        req.Port,
+		Addr:        req.Addr,
+		UUID:        req.Uuid,
+		Incarnation: req.Incarnation,
+		Rack:        req.Rack,
+		GRPCAddr:    req.GrpcAddr,
+		Versions:    req.Versions,
+	}
+	if st := req.Stats; st != nil {
+		hb.NodeStats = NodeStats{
+			TotalBytes:     st.TotalBytes,
+			FreeBytes:      st.FreeBytes,
+			UsedBytes:      st.UsedBytes,
+			ChunkCount:     int(st.ChunkCount),
+			InflightReads:  st.InflightReads,
+			InflightWrites: st.InflightWrites,
+		}
+	}
+	if err := recordHeartbeat(hb); err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpc.HeartbeatResponse{}, nil
+}
+
+func (grpcMaster) Allocate(ctx context.Context, req *rpc.AllocateRequest) (*rpc.AllocateResponse, error) {
+	file := cleanPath(req.File)
+	if file == "" || req.SizeBytes < 0 {
+		return nil, status.Error(codes.InvalidArgument, "file required, size_bytes must not be negative")
+	}
+	if req.Replication < 0 {
+		return nil, status.Error(codes.InvalidArgument, "replication must not be negative")
+	}
+	if req.ChunkSize != 0 && req.ChunkSize != ChunkSize {
+		return nil, status.Errorf(codes.InvalidArgument, "master uses %d-byte chunks, not %d", ChunkSize, req.ChunkSize)
+	}
+	alloc, err := allocateChunks(file, req.SizeBytes, int(req.Replication))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &rpc.AllocateResponse{ChunkSize: alloc.ChunkSize}
+	for i, id := range alloc.ChunkIDs {
+		resp.Chunks = append(resp.Chunks, &rpc.ChunkAllocation{ChunkId: id, Locations: alloc.Locations[i]})
+	}
+	return resp, nil
+}
+
+func (grpcMaster) ChunkLocations(ctx context.Context, req *rpc.ChunkLocationsRequest) (*rpc.ChunkLocationsResponse, error) {
+	locs, err := chunkLocations(req.ChunkId, LocalityHint{ClientHost: req.ClientHost, ClientRack: req.ClientRack})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpc.ChunkLocationsResponse{Locations: locs}, nil
+}
+
+func (grpcMaster) GetPrimary(ctx context.Context, req *rpc.PrimaryRequest) (*rpc.PrimaryResponse, error) {
+	resp, err := currentPrimary(req.ChunkId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp.proto(), nil
+}
+
+func (grpcMaster) AssignPrimary(ctx context.Context, req *rpc.PrimaryRequest) (*rpc.PrimaryResponse, error) {
+	resp, err := assignPrimary(req.ChunkId, req.Preferred)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp.proto(), nil
+}
+
+func (grpcMaster) RenewLease(ctx context.Context, req *rpc.RenewLeaseRequest) (*rpc.RenewLeaseResponse, error) {
+	resp, err := renewLease(req.ChunkId, req.Primary)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpc.RenewLeaseResponse{Ok: resp.Ok, LeaseSeconds: resp.LeaseSeconds}, nil
+}
+
+func (grpcMaster) ReportLost(ctx context.Context, req *rpc.ReportLostRequest) (*rpc.ReportLostResponse, error) {
+	err := reportLost(lostChunksRequest{Addr: req.Addr, UUID: req.Uuid, Chunks: req.Chunks})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpc.ReportLostResponse{}, nil
+}
+
+func (grpcMaster) ReportWrite(ctx context.Context, req *rpc.ReportWriteRequest) (*rpc.ReportWriteResponse, error) {
+	err := recordWrite(ReportWriteRequest{
+		ChunkID: req.ChunkId,
+		Addr:    req.Addr,
+		Length:  req.Length,
+		Version: req.Version,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpc.ReportWriteResponse{}, nil
+}
+
+func (grpcMaster) Lookup(ctx context.Context, req *rpc.FileRequest) (*rpc.LookupResponse, error) {
+	file := cleanPath(req.File)
+	if file == "" {
+		return nil, status.Error(codes.InvalidArgument, "file required")
+	}
+	resp, err := lookupFile(file, LocalityHint{ClientHost: req.ClientHost, ClientRack: req.ClientRack})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpc.LookupResponse{
+		File:      resp.File,
+		Length:    resp.Length,
+		ChunkSize: resp.ChunkSize,
+		Chunks:    protoFileChunks(resp.Chunks),
+	}, nil
+}
+
+func (grpcMaster) Stat(ctx context.Context, req *rpc.FileRequest) (*rpc.StatResponse, error) {
+	file := cleanPath(req.File)
+	if file == "" {
+		return nil, status.Error(codes.InvalidArgument, "file required")
+	}
+	resp, err := statFile(file)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpc.StatResponse{
+		File:        resp.File,
+		Length:      resp.Length,
+		ChunkSize:   resp.ChunkSize,
+		Chunks:      int32(resp.Chunks),
+		Replication: int32(resp.Replication),
+	}, nil
+}
+
+func (grpcMaster) FileChunks(ctx context.Context, req *rpc.FileChunksRequest) (*rpc.FileChunksResponse, error) {
+	resp, err := fileChunks(FileChunksRequest{
+		File:         req.File,
+		Start:        int(req.Start),
+		Count:        int(req.Count),
+		LocalityHint: LocalityHint{ClientHost: req.ClientHost, ClientRack: req.ClientRack},
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpc.FileChunksResponse{File: resp.File, Total: int32(resp.Total), Chunks: protoFileChunks(resp.Chunks)}, nil
+}
+
+func (grpcMaster) SetReplication(ctx context.Context, req *rpc.SetReplicationRequest) (*rpc.SetReplicationResponse, error) {
+	resp, err := setReplication(req.File, int(req.Replication))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpc.SetReplicationResponse{
+		File:        resp.File,
+		Replication: int32(resp.Replication),
+		Chunks:      int32(resp.Chunks),
+	}, nil
+}
+
+func protoFileChunks(fcs []FileChunk) []*rpc.FileChunk {
+	out := make([]*rpc.FileChunk, 0, len(fcs))
+	for _, fc := range fcs {
+		out = append(out, &rpc.FileChunk{
+			Index:     int32(fc.Index),
+			ChunkId:   fc.ChunkID,
+			Length:    fc.Length,
+			Locations: fc.Locations,
+		})
+	}
+	return out
+}
+
+func (p primaryResp) proto() *rpc.PrimaryResponse {
+	return &rpc.PrimaryResponse{
+		Primary:      p.Primary,
+		LeaseSeconds: p.LeaseSeconds,
+		Replicas:     p.Replicas,
+		Version:      p.Version,
+	}
+}
+
+// grpcError converts an *apiError's HTTP status to the matching gRPC code.
+func grpcError(err error) error {
+	ae, ok := err.(*apiError)
+	if !ok {
+		return status.Error(codes.Internal, err.Error())
+	}
+	code := codes.Unknown
+	switch ae.code {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.AlreadyExists
+	case http.StatusServiceUnavailable:
+		code = codes.Unavailable
+	case http.StatusInternalServerError:
+		code = codes.Internal
+	}
+	return status.Error(code, ae.msg)
+}
+
+// setupGRPCServer returns the gRPC server and its listener, or nil when
+// cfg.GRPCListenAddr is empty.
+func setupGRPCServer() (*grpc.Server, net.Listener, error) {
+	if cfg.GRPCListenAddr == "" {
+		return nil, nil, nil
+	}
+	lis, err := net.Listen("tcp", cfg.GRPCListenAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	srv := grpc.NewServer()
+	rpc.RegisterMasterServer(srv, grpcMaster{})
+	return srv, lis, nil
+}
@@ -8,6 +8,28 @@ import (
 	"time"
 )
 
+// apiError is a request failure shared by the HTTP and gRPC front ends. code
+// is the HTTP status; grpc.go maps it to a gRPC code.
+type apiError struct {
+	code int
+	msg  string
+}
+
+func (e *apiError) Error() string { return e.msg }
+
+func errorf(code int, format string, args ...any) *apiError {
+	return &apiError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+// writeError sends err to an HTTP client, as a 500 unless it is an *apiError.
+func writeError(w http.ResponseWriter, err error) {
+	if ae, ok := err.(*apiError); ok {
+		http.Error(w, ae.msg, ae.code)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
 // REGISTER HANDLER
 func registerHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -21,21 +43,60 @@ func registerHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id := "localhost:" + req.Port
+	if err := registerChunkServer(req); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Write([]byte(`{"status":"ok"}`))
+}
+
+func registerChunkServer(req RegisterRequest) error {
+	if req.Port == "" && req.Addr == "" {
+		return errorf(http.StatusBadRequest, "port or addr required")
+	}
+	id := chunkServerID(req.Port, req.Addr)
 
 	mu.Lock()
 	cs, exists := chunkServers[id]
-	if !exists {
-		cs = &ChunkServerInfo{Port: req.Port}
+	var reason string
+	switch {
+	case !exists:
+		cs = &ChunkServerInfo{Addr: id, Port: req.Port}
 		chunkServers[id] = cs
+		reason = "new chunkserver"
+	case cs.UUID == "" || req.UUID == "":
+		reason = "registration without known identity"
+	case cs.UUID == req.UUID:
+		reason = "restart"
+	case cs.Alive && time.Since(cs.lastSeen) < heartbeatTimeout:
+		// the registered node is still heartbeating: two processes claim
+		// one address
+		cs.Conflict = fmt.Sprintf("uuid %s incarnation %s also claims %s", req.UUID, req.Incarnation, id)
+		owner := cs.UUID
+		mu.Unlock()
+		log.Printf("master: address conflict on %s: registered uuid=%s, new uuid=%s", id, owner, req.UUID)
+		return errorf(http.StatusConflict, "address already registered by another chunkserver")
+	default:
+		// same address, different persistent ID, and the old identity has
+		// gone silent: the data dir is not the one we knew
+		reason = fmt.Sprintf("disk replaced (uuid %s -> %s)", cs.UUID, req.UUID)
+	}
+	cs.UUID = req.UUID
+	cs.Incarnation = req.Incarnation
+	cs.Rack = req.Rack
+	cs.GRPCAddr = req.GRPCAddr
+	cs.Conflict = ""
+	if endMaintenanceOnReturn(id, cs) {
+		logMaintenance(id, 0)
 	}
 	cs.lastSeen = time.Now()
 	cs.LastSeenUnix = cs.lastSeen.Unix()
 	cs.Alive = true
 	mu.Unlock()
 
-	log.Printf("master: registered chunkserver %s", id)
-	w.Write([]byte(`{"status":"ok"}`))
+	log.Printf("master: registered chunkserver %s (%s)", id, reason)
+	go reconcileNode(id, reason)
+	return nil
 }
 
 // HEARTBEAT HANDLER
@@ -51,21 +112,88 @@ func heartbeatHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id := "localhost:" + req.Port
+	if err := recordHeartbeat(req); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Write([]byte(`{"status":"ok"}`))
+}
+
+func recordHeartbeat(req HeartbeatRequest) error {
+	if req.Port == "" && req.Addr == "" {
+		return errorf(http.StatusBadRequest, "port or addr required")
+	}
+	id := chunkServerID(req.Port, req.Addr)
 
 	mu.Lock()
 	cs, exists := chunkServers[id]
-	if !exists {
-		cs = &ChunkServerInfo{Port: req.Port}
+	reason := ""
+	switch {
+	case !exists:
+		cs = &ChunkServerInfo{Addr: id, Port: req.Port}
 		chunkServers[id] = cs
+		reason = "heartbeat from unknown chunkserver"
+	case cs.UUID == "" && req.UUID != "":
+		reason = "first heartbeat with identity"
+	case identityMismatch(cs, req.UUID, req.Incarnation):
+		// another process is heartbeating for an address already owned by the registered one
+		cs.Conflict = fmt.Sprintf("uuid %s incarnation %s also claims %s", req.UUID, req.Incarnation, id)
+		owner := cs.UUID
+		mu.Unlock()
+		log.Printf("master: address conflict on %s: registered uuid=%s, heartbeat uuid=%s", id, owner, req.UUID)
+		go reconcileNode(id, "address conflict")
+		return errorf(http.StatusConflict, "address already registered by another chunkserver")
+	case !cs.Alive:
+		reason = "chunkserver back after being marked dead"
+	case cs.lastSeen.IsZero():
+		// known from the checkpoint; chunks allocated since then were
+		// replayed from the op-log without their replicas
+		reason = "first heartbeat since master restart"
+	}
+	if req.UUID != "" {
+		cs.UUID = req.UUID
+		cs.Incarnation = req.Incarnation
+	}
+	if req.Rack != "" {
+		cs.Rack = req.Rack
+	}
+	if req.GRPCAddr != "" {
+		cs.GRPCAddr = req.GRPCAddr
+	}
+	cs.NodeStats = req.NodeStats
+	if !cs.Alive && endMaintenanceOnReturn(id, cs) {
+		logMaintenance(id, 0)
 	}
 	cs.lastSeen = time.Now()
 	cs.LastSeenUnix = cs.lastSeen.Unix()
 	cs.Alive = true
+	stale, underReplicated := dropStaleLocked(id, req.Versions)
 	mu.Unlock()
 
+	if len(stale) > 0 {
+		log.Printf("master: %s holds %d stale replicas, dropping them", id, len(stale))
+		go deleteStale(id, stale)
+	}
+	for _, cid := range underReplicated {
+		repairs.enqueue(cid, id)
+	}
+	if reason != "" {
+		go reconcileNode(id, reason)
+	}
 	log.Printf("master: heartbeat from %s", id)
-	w.Write([]byte(`{"status":"ok"}`))
+	return nil
+}
+
+// identityMismatch reports whether a heartbeat's identity differs from the one
+// the chunkserver last registered with. Callers must hold mu.
+func identityMismatch(cs *ChunkServerInfo, uuid, inc string) bool {
+	if cs.UUID == "" || uuid == "" {
+		return false
+	}
+	if cs.UUID != uuid {
+		return true
+	}
+	return cs.Incarnation != "" && inc != "" && cs.Incarnation != inc
 }
 
 // LIST HANDLER
@@ -74,9 +202,19 @@ func listHandler(w http.ResponseWriter, r *http.Request) {
 	out := make(map[string]ChunkServerInfo)
 	for id, cs := range chunkServers {
 		out[id] = ChunkServerInfo{
+			Addr:         cs.Addr,
 			Port:         cs.Port,
+			Rack:         cs.Rack,
+			GRPCAddr:     cs.GRPCAddr,
+			UUID:         cs.UUID,
+			Conflict:     cs.Conflict,
+			NodeStats:    cs.NodeStats,
 			LastSeenUnix: cs.LastSeenUnix,
 			Alive:        cs.Alive,
+			Draining:     cs.Draining,
+			Drained:      cs.Drained,
+
+			MaintenanceUntil: cs.MaintenanceUntil,
 		}
 	}
 	mu.Unlock()
@@ -97,14 +235,23 @@ func allocateHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid json", http.StatusBadRequest)
 		return
 	}
-	if req.File == "" || req.SizeBytes <= 0 {
-		http.Error(w, "file and positive size_bytes required", http.StatusBadRequest)
+	req.File = cleanPath(req.File)
+	if req.File == "" || req.SizeBytes < 0 {
+		http.Error(w, "file required, size_bytes must not be negative", http.StatusBadRequest)
+		return
+	}
+	if req.Replication < 0 {
+		http.Error(w, "replication must not be negative", http.StatusBadRequest)
+		return
+	}
+	if req.ChunkSize != 0 && req.ChunkSize != ChunkSize {
+		http.Error(w, fmt.Sprintf("master uses %d-byte chunks, not %d", ChunkSize, req.ChunkSize), http.StatusBadRequest)
 		return
 	}
 
-	resp, err := allocateChunks(req.File, req.SizeBytes)
+	resp, err := allocateChunks(req.File, req.SizeBytes, req.Replication)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		writeError(w, err)
 		return
 	}
 
@@ -115,32 +262,54 @@ func allocateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// allocateChunks: create ceil(size / ChunkSize) chunk metas and assign replicas per chunk
-func allocateChunks(file string, sizeBytes int64) (*AllocateResponse, error) {
+// allocateChunks: create ceil(size / ChunkSize) chunk metas and assign replicas per chunk.
+// replication only applies when the file is new; 0 uses the configured default.
+// size 0 only creates the file, so empty files exist too.
+func allocateChunks(file string, sizeBytes int64, replication int) (*AllocateResponse, error) {
 	mu.Lock()
 	defer mu.Unlock()
 
-	// collect alive nodes
-	var alive []string
-	for id, cs := range chunkServers {
-		if cs.Alive {
-			alive = append(alive, id)
-		}
+	// compute number of chunks
+	num := int((sizeBytes + ChunkSize - 1) / ChunkSize)
+
+	fm, exist := files[file]
+	if exist && num == 0 {
+		return &AllocateResponse{ChunkIDs: []string{}, Locations: [][]string{}, ChunkSize: ChunkSize}, nil
 	}
-	if len(alive) < replicationFactor {
-		return nil, fmt.Errorf("not enough alive chunk-servers: have %d, need %d", len(alive), replicationFactor)
+	want := replication
+	if exist {
+		want = fileReplication(fm)
+		// a partly written or abandoned last chunk would end up in the
+		// middle of the file and shift every offset after it. One that was
+		// just allocated is left to its writer: UploadFrom allocates the
+		// next chunk while the previous one is still being written.
+		if n := len(fm.Chunks); n > 0 {
+			if last, ok := chunks[fm.Chunks[n-1]]; ok {
+				switch {
+				case last.Length > 0 && last.Length < ChunkSize:
+					return nil, errorf(http.StatusConflict, "last chunk of %s is partly written; fill it before allocating more", file)
+				case last.Length == 0 && !last.awaitingWrite():
+					return nil, errorf(http.StatusConflict, "last chunk of %s was allocated but never written; fill it before allocating more", file)
+				}
+			}
+		}
+	} else {
+		if err := checkCreateLocked(file); err != nil {
+			return nil, err
+		}
+		if want == 0 {
+			want = replicationFactor
+		}
 	}
 
-	// compute number of chunks
-	num := int((sizeBytes + ChunkSize - 1) / ChunkSize)
-	if num <= 0 {
-		num = 1
+	// collect alive nodes with room
+	if n := len(placementCandidates(nil)); num > 0 && n < want {
+		return nil, errorf(http.StatusServiceUnavailable, "not enough alive chunk-servers with free space: have %d, need %d", n, want)
 	}
 
-	// get or create file metadata
-	fm, exist := files[file]
+	// create file metadata if needed
 	if !exist {
-		fm = &FileMeta{Name: file}
+		fm = &FileMeta{Name: file, Replication: replication}
 		files[file] = fm
 	}
 
@@ -149,21 +318,22 @@ func allocateChunks(file string, sizeBytes int64) (*AllocateResponse, error) {
 
 	for i := 0; i < num; i++ {
 		index := len(fm.Chunks)
-		chunkID := fmt.Sprintf("%s_%d", file, index)
-
-		// choose replicas: simple round-robin slice of alive servers
-		// rotate so chunk placement spreads across nodes
-		start := index % len(alive)
-		replicas := make([]string, 0, replicationFactor)
-		for j := 0; j < replicationFactor; j++ {
-			replicas = append(replicas, alive[(start+j)%len(alive)])
+		chunkID := newChunkID(file, index)
+
+		// choose replicas via the placement policy; recording each creation
+		// steers the next chunk of this allocation elsewhere
+		now := time.Now()
+		replicas := placement.Place(want, placementCandidates(nil), nil)
+		for _, id := range replicas {
+			chunkServers[id].noteCreate(now)
 		}
 
 		cm := &ChunkMeta{
-			ID:       chunkID,
-			FileName: file,
-			Index:    index,
-			Replicas: replicas,
+			ID:        chunkID,
+			FileName:  file,
+			Index:     index,
+			Replicas:  replicas,
+			allocated: now,
 		}
 
 		chunks[chunkID] = cm
@@ -177,13 +347,15 @@ func allocateChunks(file string, sizeBytes int64) (*AllocateResponse, error) {
 	}
 
 	appendOpLog("allocate", map[string]any{
-		"file":   file,
-		"chunks": chunkIDs,
+		"file":        file,
+		"chunks":      chunkIDs,
+		"replication": fm.Replication,
 	})
 
 	return &AllocateResponse{
 		ChunkIDs:  chunkIDs,
 		Locations: locations,
+		ChunkSize: ChunkSize,
 	}, nil
 }
 
@@ -202,23 +374,252 @@ func ChunkLocationsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	locs, err := chunkLocations(req.ChunkID, req.LocalityHint)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	resp := ChunkLocationsResponse{
+		Locations: locs,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// chunkLocations returns the replicas of chunkID, nearest hint first.
+func chunkLocations(chunkID string, hint LocalityHint) ([]string, error) {
 	mu.Lock()
-	cm, ok := chunks[req.ChunkID]
-	mu.Unlock()
+	defer mu.Unlock()
 
+	cm, ok := chunks[chunkID]
 	if !ok {
-		http.Error(w, "chunk not found", http.StatusNotFound)
+		return nil, errorf(http.StatusNotFound, "chunk not found")
+	}
+	return orderReplicas(append([]string(nil), cm.Replicas...), hint), nil
+}
+
+// /file_chunks : chunk IDs and locations for a range of a file's chunk
+// indexes, so clients can resolve many chunks in one round trip
+func fileChunksHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	defer r.Body.Close()
 
-	resp := ChunkLocationsResponse{
-		Locations: cm.Replicas,
+	var req FileChunksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	resp, err := fileChunks(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func fileChunks(req FileChunksRequest) (*FileChunksResponse, error) {
+	req.File = cleanPath(req.File)
+	if req.File == "" || req.Start < 0 || req.Count < 0 {
+		return nil, errorf(http.StatusBadRequest, "file required, start and count must not be negative")
 	}
 
+	mu.Lock()
+	defer mu.Unlock()
+
+	fm, ok := files[req.File]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "file not found")
+	}
+	end := len(fm.Chunks)
+	if req.Count > 0 {
+		end = min(end, req.Start+req.Count)
+	}
+	resp := &FileChunksResponse{File: req.File, Total: len(fm.Chunks), Chunks: []FileChunk{}}
+	for i := req.Start; i < end; i++ {
+		fc := FileChunk{Index: i, ChunkID: fm.Chunks[i]}
+		if cm, ok := chunks[fc.ChunkID]; ok {
+			fc.Length = cm.Length
+			fc.Locations = orderReplicas(append([]string(nil), cm.Replicas...), req.LocalityHint)
+		}
+		resp.Chunks = append(resp.Chunks, fc)
+	}
+	return resp, nil
+}
+
+// /lookup : a file's ordered chunks with their replicas, its length and the
+// chunk size, so any process can read a file by name
+func lookupHandler(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeFileRequest(w, r)
+	if !ok {
+		return
+	}
+	resp, err := lookupFile(req.File, req.LocalityHint)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
 
+func lookupFile(name string, hint LocalityHint) (*LookupResponse, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	fm, ok := files[name]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "file not found")
+	}
+	resp := &LookupResponse{File: name, Length: fm.Length, ChunkSize: ChunkSize, Chunks: make([]FileChunk, 0, len(fm.Chunks))}
+	for i, id := range fm.Chunks {
+		fc := FileChunk{Index: i, ChunkID: id}
+		if cm, ok := chunks[id]; ok {
+			fc.Length = cm.Length
+			fc.Locations = orderReplicas(append([]string(nil), cm.Replicas...), hint)
+		}
+		resp.Chunks = append(resp.Chunks, fc)
+	}
+	return resp, nil
+}
+
+// /stat : a file's length and chunk count without its chunk list
+func statHandler(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeFileRequest(w, r)
+	if !ok {
+		return
+	}
+	resp, err := statFile(req.File)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func statFile(name string) (*StatResponse, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	fm, ok := files[name]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "file not found")
+	}
+	return &StatResponse{
+		File:        name,
+		Length:      fm.Length,
+		ChunkSize:   ChunkSize,
+		Chunks:      len(fm.Chunks),
+		Replication: fileReplication(fm),
+	}, nil
+}
+
+// /report_write : a primary tells us how many bytes of a chunk a committed
+// write left valid, which moves the file's length
+func reportWriteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	var req ReportWriteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if err := recordWrite(req); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Write([]byte(`{"status":"ok"}`))
+}
+
+// recordWrite sets the committed length of a chunk and recomputes its
+// file's length. Only the lease holder may report, while its lease lasts.
+func recordWrite(req ReportWriteRequest) error {
+	if req.ChunkID == "" {
+		return errorf(http.StatusBadRequest, "chunk_id required")
+	}
+	if req.Length < 0 || req.Length > ChunkSize {
+		return errorf(http.StatusBadRequest, "length must be between 0 and the chunk size")
+	}
+
+	mu.Lock()
+	cm, ok := chunks[req.ChunkID]
+	if !ok {
+		mu.Unlock()
+		return errorf(http.StatusNotFound, "chunk not found")
+	}
+	if req.Addr == "" || req.Addr != cm.Primary || !cm.LeaseValid() {
+		mu.Unlock()
+		return errorf(http.StatusConflict, "%s does not hold the lease", req.Addr)
+	}
+	// readers find a chunk by offset/ChunkSize, so only the last chunk of a
+	// file may be short
+	fm, ok := files[cm.FileName]
+	if ok && req.Length < ChunkSize && cm.Index < len(fm.Chunks)-1 {
+		mu.Unlock()
+		return errorf(http.StatusConflict, "chunk %d of %s is not the last and must be full", cm.Index, cm.FileName)
+	}
+	cm.Length = req.Length
+	cm.Version = max(cm.Version, req.Version)
+	var fileLength int64
+	if ok {
+		fm.Length = fileLengthLocked(fm)
+		fileLength = fm.Length
+	}
+	appendOpLog("chunk_length", map[string]any{
+		"chunk_id":    req.ChunkID,
+		"length":      req.Length,
+		"version":     cm.Version,
+		"file":        cm.FileName,
+		"file_length": fileLength,
+	})
+	mu.Unlock()
+	return nil
+}
+
+// fileLengthLocked adds up the committed lengths of fm's chunks. Callers
+// must hold mu.
+func fileLengthLocked(fm *FileMeta) int64 {
+	var n int64
+	for _, id := range fm.Chunks {
+		if cm, ok := chunks[id]; ok {
+			n += cm.Length
+		}
+	}
+	return n
+}
+
+// decodeFileRequest reads a FileRequest from a POST body, answering the
+// client itself when the request is bad.
+func decodeFileRequest(w http.ResponseWriter, r *http.Request) (FileRequest, bool) {
+	var req FileRequest
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return req, false
+	}
+	defer r.Body.Close()
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return req, false
+	}
+	req.File = cleanPath(req.File)
+	if req.File == "" {
+		http.Error(w, "file required", http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
 func clusterInfoHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
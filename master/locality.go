@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net"
+	"sort"
+)
+
+// LocalityHint tells the master where a reader runs, so replicas close to it
+// can be listed first. Both fields are optional.
+type LocalityHint struct {
+	ClientHost string `json:"client_host,omitempty"` // host part of a chunkserver address
+	ClientRack string `json:"client_rack,omitempty"`
+}
+
+// Replica tiers, nearest first.
+const (
+	tierSameHost = iota
+	tierSameRack
+	tierOther
+	tierDown
+)
+
+// orderReplicas sorts replicas in place for a reader at hint: same host, then
+// same rack, then the rest, with dead chunkservers last. The master's order
+// is kept within a tier. Callers must hold mu.
+func orderReplicas(replicas []string, hint LocalityHint) []string {
+	if len(replicas) < 2 {
+		return replicas
+	}
+	tier := func(id string) int {
+		cs, ok := chunkServers[id]
+		if !ok || !cs.Alive {
+			return tierDown
+		}
+		if hint.ClientHost != "" {
+			if host, _, err := net.SplitHostPort(cs.Addr); err == nil && host == hint.ClientHost {
+				return tierSameHost
+			}
+		}
+		if hint.ClientRack != "" && cs.Rack == hint.ClientRack {
+			return tierSameRack
+		}
+		return tierOther
+	}
+	sort.SliceStable(replicas, func(i, j int) bool {
+		return tier(replicas[i]) < tier(replicas[j])
+	})
+	return replicas
+}
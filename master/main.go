@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,12 +16,30 @@ import (
 func main() {
 	log.SetFlags(0)
 
+	c, err := loadConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("\033[31mmaster:\033[0m config error: %v\n", err)
+	}
+	applyConfig(c)
+	if err := os.MkdirAll(c.DataDir, 0755); err != nil {
+		log.Fatalf("\033[31mmaster:\033[0m cannot create data dir %s: %v\n", c.DataDir, err)
+	}
+
 	// load persisted state (checkpoint + op-log) before starting services
 	loadCheckpoint()
 	replayOpLog()
 
 	srv := setupServer()
+	grpcSrv, grpcLis, err := setupGRPCServer()
+	if err != nil {
+		log.Fatalf("\033[31mmaster:\033[0m cannot listen for gRPC: %v\n", err)
+	}
 	go sweeper()
+	go repairs.run()
+	go replicationScanner()
 
 	go func() {
 		fmt.Printf("\033[31mmaster:\033[0m server starting on port: %s\n", srv.Addr)
@@ -28,6 +48,15 @@ func main() {
 		}
 	}()
 
+	if grpcSrv != nil {
+		go func() {
+			fmt.Printf("\033[31mmaster:\033[0m gRPC server starting on %s\n", grpcLis.Addr())
+			if err := grpcSrv.Serve(grpcLis); err != nil {
+				log.Fatalf("\033[31mmaster:\033[0m error serving gRPC: %v\n", err)
+			}
+		}()
+	}
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 
@@ -37,6 +66,9 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if grpcSrv != nil {
+		grpcSrv.GracefulStop()
+	}
 	if err := srv.Shutdown(ctx); err != nil {
 		fmt.Printf("\033[31mmaster:\033[0m graceful shutdown failed: %v\n", err)
 	} else {
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+type maintenanceRequest struct {
+	Node     string   `json:"node"`
+	Duration Duration `json:"duration,omitempty"` // "15m" style or seconds
+	End      bool     `json:"end,omitempty"`      // leave maintenance now
+}
+
+type maintenanceStatus struct {
+	Node      string `json:"node"`
+	Alive     bool   `json:"alive"`
+	UntilUnix int64  `json:"until_unix"`
+	Remaining string `json:"remaining"`
+}
+
+// inMaintenance reports whether cs is inside a planned maintenance window.
+func (cs *ChunkServerInfo) inMaintenance(now time.Time) bool {
+	return cs.MaintenanceUntil != 0 && now.Unix() < cs.MaintenanceUntil
+}
+
+// checkMaintenance ends windows whose deadline has passed. A node that is
+// still down at that point gets the repair its death would have started.
+// Callers must hold mu.
+func checkMaintenance(now time.Time) {
+	for id, cs := range chunkServers {
+		if cs.MaintenanceUntil == 0 || cs.inMaintenance(now) {
+			continue
+		}
+		cs.MaintenanceUntil = 0
+		if cs.Alive {
+			log.Printf("master: maintenance window of %s ended", id)
+			continue
+		}
+		log.Printf("master: %s did not return before its maintenance deadline, starting repair", id)
+		go repairNode(id)
+	}
+}
+
+// endMaintenanceOnReturn closes the maintenance window of a node that has
+// come back from its restart. It reports whether there was one to close.
+// Callers must hold mu.
+func endMaintenanceOnReturn(id string, cs *ChunkServerInfo) bool {
+	if !cs.inMaintenance(time.Now()) {
+		return false
+	}
+	cs.MaintenanceUntil = 0
+	log.Printf("master: %s is back, maintenance window closed", id)
+	return true
+}
+
+// logMaintenance records node id's maintenance deadline. Callers must hold
+// mu.
+func logMaintenance(id string, until int64) {
+	appendOpLog("maintenance", map[string]any{
+		"node":  id,
+		"until": until,
+	})
+}
+
+// /maintenance : POST {"node": "host:port", "duration": "15m"} puts a node in
+// maintenance. Until the deadline, or until the node re-registers, it gets no
+// new replicas or leases and going down doesn't trigger repair. "end": true
+// closes the window early. GET lists nodes in maintenance.
+func maintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		now := time.Now()
+		out := []maintenanceStatus{}
+		mu.Lock()
+		for id, cs := range chunkServers {
+			if cs.inMaintenance(now) {
+				out = append(out, maintenanceStatus{
+					Node:      id,
+					Alive:     cs.Alive,
+					UntilUnix: cs.MaintenanceUntil,
+					Remaining: time.Unix(cs.MaintenanceUntil, 0).Sub(now).Round(time.Second).String(),
+				})
+			}
+		}
+		mu.Unlock()
+		sort.Slice(out, func(i, j int) bool { return out[i].Node < out[j].Node })
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(out)
+		return
+
+	case http.MethodPost:
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	var req maintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.Node == "" || (!req.End && req.Duration <= 0) {
+		http.Error(w, "node and a positive duration required", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	mu.Lock()
+	cs, ok := chunkServers[req.Node]
+	if !ok {
+		mu.Unlock()
+		http.Error(w, "chunkserver not found", http.StatusNotFound)
+		return
+	}
+	var until int64
+	if !req.End {
+		until = now.Add(time.Duration(req.Duration)).Unix()
+	}
+	cs.MaintenanceUntil = until
+	// ending early while the node is down hands it to the deadline check,
+	// which starts the deferred repair
+	if req.End && !cs.Alive {
+		cs.MaintenanceUntil = now.Unix()
+	}
+	st := maintenanceStatus{Node: req.Node, Alive: cs.Alive, UntilUnix: cs.MaintenanceUntil}
+	// log what was applied, so a restart still finds the ended window of a
+	// down node and starts its repair
+	logMaintenance(req.Node, st.UntilUnix)
+	mu.Unlock()
+
+	if req.End {
+		log.Printf("master: maintenance of %s ended by request", req.Node)
+	} else {
+		st.Remaining = time.Duration(req.Duration).String()
+		log.Printf("master: %s in maintenance for %s", req.Node, st.Remaining)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+)
+
+// The namespace is flat: files are keyed by their full slash-separated path.
+// A directory exists while files live under it, or once it is made with
+// /mkdir; explicit directories are kept in dirs.
+
+// ListRequest asks /ls for the entries under Path, or only Path if it is a
+// file. Recursive lists the whole subtree.
+type ListRequest struct {
+	Path      string `json:"path"`
+	Recursive bool   `json:"recursive,omitempty"`
+}
+
+type DirEntry struct {
+	Name        string `json:"name"` // full path
+	Dir         bool   `json:"dir,omitempty"`
+	Length      int64  `json:"length"`
+	Chunks      int    `json:"chunks"`
+	Replication int    `json:"replication,omitempty"`
+}
+
+type ListResponse struct {
+	Path    string     `json:"path"`
+	Entries []DirEntry `json:"entries"`
+}
+
+type MkdirRequest struct {
+	Path    string `json:"path"`
+	Parents bool   `json:"parents,omitempty"` // make missing parents, and don't fail if Path exists
+}
+
+type DeleteRequest struct {
+	Path      string `json:"path"`
+	Recursive bool   `json:"recursive,omitempty"` // required to delete a non-empty directory
+}
+
+type RenameRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// cleanPath normalizes a file or directory name: no leading slash, no
+// empty, . or .. elements. The root is "".
+func cleanPath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
+
+// dirPrefix is what the names under directory dir start with.
+func dirPrefix(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	return dir + "/"
+}
+
+// isDirLocked reports whether p is a directory. Callers must hold mu.
+func isDirLocked(p string) bool {
+	if p == "" || dirs[p] {
+		return true
+	}
+	prefix := dirPrefix(p)
+	for name := range files {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	for d := range dirs {
+		if strings.HasPrefix(d, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCreateLocked returns a conflict if a file can't be created at p
+// because p is a directory or one of its parents is a file. Callers must
+// hold mu.
+func checkCreateLocked(p string) error {
+	if isDirLocked(p) {
+		return errorf(http.StatusConflict, "%s is a directory", p)
+	}
+	for d := path.Dir(p); d != "."; d = path.Dir(d) {
+		if _, ok := files[d]; ok {
+			return errorf(http.StatusConflict, "%s is not a directory", d)
+		}
+	}
+	return nil
+}
+
+// newChunkID names chunk index of file. IDs are file names on the
+// chunkservers, so slashes are escaped, and a random suffix keeps a file
+// that is deleted or renamed and then re-created from reusing the IDs of
+// copies still on disk. Callers must hold mu.
+func newChunkID(file string, index int) string {
+	base := fmt.Sprintf("%s_%d", strings.ReplaceAll(file, "/", "%2F"), index)
+	for {
+		b := make([]byte, 4)
+		rand.Read(b)
+		id := base + "_" + hex.EncodeToString(b)
+		if _, taken := chunks[id]; !taken {
+			return id
+		}
+	}
+}
+
+func fileEntry(fm *FileMeta) DirEntry {
+	return DirEntry{
+		Name:        fm.Name,
+		Length:      fm.Length,
+		Chunks:      len(fm.Chunks),
+		Replication: fileReplication(fm),
+	}
+}
+
+// /ls : list a directory, or stat a single file as a one-entry listing
+func lsHandler(w http.ResponseWriter, r *http.Request) {
+	var req ListRequest
+	if !decodePost(w, r, &req) {
+		return
+	}
+	resp, err := listPath(req.Path, req.Recursive)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func listPath(p string, recursive bool) (*ListResponse, error) {
+	p = cleanPath(p)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	resp := &ListResponse{Path: p, Entries: []DirEntry{}}
+	if fm, ok := files[p]; ok {
+		resp.Entries = append(resp.Entries, fileEntry(fm))
+		return resp, nil
+	}
+	if !isDirLocked(p) {
+		return nil, errorf(http.StatusNotFound, "%s: no such file or directory", p)
+	}
+
+	// files are listed as they are; each directory is listed once, whether
+	// it is explicit or implied by the names under it
+	prefix := dirPrefix(p)
+	seen := make(map[string]bool)
+	addDirs := func(rest string) {
+		parts := strings.Split(rest, "/")
+		n := len(parts)
+		if !recursive {
+			n = 1
+		}
+		for i := 1; i <= n && i <= len(parts); i++ {
+			d := prefix + strings.Join(parts[:i], "/")
+			if !seen[d] {
+				seen[d] = true
+				resp.Entries = append(resp.Entries, DirEntry{Name: d, Dir: true})
+			}
+		}
+	}
+	for name, fm := range files {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		dir, _, nested := strings.Cut(rest, "/")
+		switch {
+		case !nested:
+			resp.Entries = append(resp.Entries, fileEntry(fm))
+		case recursive:
+			addDirs(path.Dir(rest))
+			resp.Entries = append(resp.Entries, fileEntry(fm))
+		default:
+			addDirs(dir)
+		}
+	}
+	for d := range dirs {
+		if rest, ok := strings.CutPrefix(d, prefix); ok && rest != "" {
+			addDirs(rest)
+		}
+	}
+	sort.Slice(resp.Entries, func(i, j int) bool {
+		return resp.Entries[i].Name < resp.Entries[j].Name
+	})
+	return resp, nil
+}
+
+// /mkdir : make a directory, and its parents when asked
+func mkdirHandler(w http.ResponseWriter, r *http.Request) {
+	var req MkdirRequest
+	if !decodePost(w, r, &req) {
+		return
+	}
+	if err := makeDir(req.Path, req.Parents); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Write([]byte(`{"status":"ok"}`))
+}
+
+func makeDir(p string, parents bool) error {
+	p = cleanPath(p)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := files[p]; ok {
+		return errorf(http.StatusConflict, "%s: file exists", p)
+	}
+	if isDirLocked(p) {
+		if parents {
+			return nil
+		}
+		return errorf(http.StatusConflict, "%s: directory exists", p)
+	}
+	if err := checkCreateLocked(p); err != nil {
+		return err
+	}
+	var made []string
+	for d := path.Dir(p); d != "."; d = path.Dir(d) {
+		if isDirLocked(d) {
+			break
+		}
+		if !parents {
+			return errorf(http.StatusNotFound, "%s: no such directory", d)
+		}
+		made = append(made, d)
+	}
+	made = append(made, p)
+	for _, d := range made {
+		dirs[d] = true
+	}
+
+	appendOpLog("mkdir", map[string]any{"dirs": made})
+	return nil
+}
+
+// /delete : remove a file, or a directory and everything under it. The
+// metadata goes at once; replicas are deleted from the chunkservers in the
+// background, and any that are missed are left for the orphan count.
+func deleteHandler(w http.ResponseWriter, r *http.Request) {
+	var req DeleteRequest
+	if !decodePost(w, r, &req) {
+		return
+	}
+	if err := deletePath(req.Path, req.Recursive); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Write([]byte(`{"status":"ok"}`))
+}
+
+func deletePath(p string, recursive bool) error {
+	p = cleanPath(p)
+	if p == "" {
+		return errorf(http.StatusBadRequest, "cannot delete the root directory")
+	}
+
+	mu.Lock()
+	var names, dirNames []string
+	if _, ok := files[p]; ok {
+		names = append(names, p)
+	} else if isDirLocked(p) {
+		prefix := dirPrefix(p)
+		for name := range files {
+			if strings.HasPrefix(name, prefix) {
+				names = append(names, name)
+			}
+		}
+		empty := len(names) == 0
+		for d := range dirs {
+			if d == p || strings.HasPrefix(d, prefix) {
+				dirNames = append(dirNames, d)
+				empty = empty && d == p
+			}
+		}
+		if !recursive && !empty {
+			mu.Unlock()
+			return errorf(http.StatusConflict, "%s: directory not empty", p)
+		}
+	} else {
+		mu.Unlock()
+		return errorf(http.StatusNotFound, "%s: no such file or directory", p)
+	}
+
+	doomed := make(map[string][]string) // chunk ID -> live replicas
+	for _, name := range names {
+		for _, cid := range files[name].Chunks {
+			if cm, ok := chunks[cid]; ok {
+				for _, node := range cm.Replicas {
+					if cs, ok := chunkServers[node]; ok && cs.Alive {
+						doomed[cid] = append(doomed[cid], node)
+					}
+				}
+			}
+		}
+	}
+	removeLocked(names, dirNames)
+	appendOpLog("delete", map[string]any{"files": names, "dirs": dirNames})
+	mu.Unlock()
+
+	log.Printf("master: deleted %s (%d files, %d chunks)", p, len(names), len(doomed))
+
+	go func() {
+		for cid, nodes := range doomed {
+			for _, node := range nodes {
+				if err := deleteChunkOn(node, cid); err != nil {
+					log.Printf("master: deleting chunk %s: %v", cid, err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// removeLocked drops the named files with their chunks, and the named
+// explicit directories. Callers must hold mu.
+func removeLocked(names, dirNames []string) {
+	for _, name := range names {
+		fm, ok := files[name]
+		if !ok {
+			continue
+		}
+		for _, cid := range fm.Chunks {
+			delete(chunks, cid)
+		}
+		delete(files, name)
+	}
+	for _, d := range dirNames {
+		delete(dirs, d)
+	}
+}
+
+// /rename : move a file or a directory with everything under it
+func renameHandler(w http.ResponseWriter, r *http.Request) {
+	var req RenameRequest
+	if !decodePost(w, r, &req) {
+		return
+	}
+	if err := renamePath(req.From, req.To); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Write([]byte(`{"status":"ok"}`))
+}
+
+func renamePath(from, to string) error {
+	from, to = cleanPath(from), cleanPath(to)
+	if from == "" || to == "" {
+		return errorf(http.StatusBadRequest, "cannot rename the root directory")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	_, isFile := files[from]
+	if !isFile && !isDirLocked(from) {
+		return errorf(http.StatusNotFound, "%s: no such file or directory", from)
+	}
+	if _, ok := files[to]; ok || isDirLocked(to) {
+		return errorf(http.StatusConflict, "%s: already exists", to)
+	}
+	if strings.HasPrefix(to, from+"/") {
+		return errorf(http.StatusBadRequest, "cannot move %s into itself", from)
+	}
+	if err := checkCreateLocked(to); err != nil {
+		return err
+	}
+	renameLocked(from, to)
+
+	appendOpLog("rename", map[string]any{"from": from, "to": to})
+	log.Printf("master: renamed %s to %s", from, to)
+	return nil
+}
+
+// renameLocked moves the file from, or every file and explicit directory
+// under from, to to. Chunk IDs stay as they are. Callers must hold mu.
+func renameLocked(from, to string) {
+	move := func(old, name string) {
+		fm := files[old]
+		delete(files, old)
+		fm.Name = name
+		files[name] = fm
+		for _, cid := range fm.Chunks {
+			if cm, ok := chunks[cid]; ok {
+				cm.FileName = name
+			}
+		}
+	}
+	if _, ok := files[from]; ok {
+		move(from, to)
+		return
+	}
+	prefix := from + "/"
+	for name := range files {
+		if rest, ok := strings.CutPrefix(name, prefix); ok {
+			move(name, to+"/"+rest)
+		}
+	}
+	for d := range dirs {
+		if d == from {
+			delete(dirs, d)
+			dirs[to] = true
+		} else if rest, ok := strings.CutPrefix(d, prefix); ok {
+			delete(dirs, d)
+			dirs[to+"/"+rest] = true
+		}
+	}
+}
+
+// decodePost reads a JSON POST body into v, answering the client itself
+// when the request is bad.
+func decodePost(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	defer r.Body.Close()
+
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
@@ -6,6 +6,10 @@ import (
 	"os"
 )
 
+// appendOpLog adds an entry to the op-log. Callers must hold mu, the same
+// hold under which they made the change: writeCheckpoint truncates the log
+// under mu, so an entry written after unlocking could land after a
+// checkpoint that already holds its change and be replayed on top of it.
 func appendOpLog(event string, payload any) {
 	entry := map[string]any{
 		"event":   event,
@@ -14,7 +18,7 @@ func appendOpLog(event string, payload any) {
 
 	b, _ := json.Marshal(entry)
 
-	f, err := os.OpenFile("oplog.jsonl", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	f, err := os.OpenFile(opLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		log.Printf("master: op-log open error: %v", err)
 		return
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"log"
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+// PlacementCandidate is the view of a chunkserver a PlacementPolicy decides on.
+type PlacementCandidate struct {
+	ID              string
+	Rack            string // failure domain; "" when the node didn't declare one
+	Stats           NodeStats
+	RecentCreates   float64 // chunks placed here lately, decayed over time
+	InflightRepairs int     // copies currently being made to or from this node
+}
+
+// PlacementPolicy picks chunkservers to host new replicas. It is used both
+// when allocating chunks and when repair needs a new target.
+type PlacementPolicy interface {
+	// Place returns up to n distinct candidate IDs, best first. usedRacks
+	// holds the racks of replicas the chunk already has, which the policy
+	// should avoid so replicas end up in different failure domains.
+	Place(n int, candidates []PlacementCandidate, usedRacks []string) []string
+}
+
+// placement is the policy used by allocateChunks and pickRepair.
+var placement PlacementPolicy = weightedPlacement{
+	CreateWeight: 0.1,
+	RepairWeight: 0.5,
+}
+
+// weightedPlacement prefers nodes with a large free fraction and discounts
+// nodes that just received chunks or are busy with repairs, so a burst of
+// allocations spreads out instead of piling onto the emptiest node. Among
+// those it first takes nodes in racks the chunk doesn't use yet, and only
+// doubles up on a rack when there aren't enough racks to go around.
+type weightedPlacement struct {
+	CreateWeight float64
+	RepairWeight float64
+}
+
+func (p weightedPlacement) score(c PlacementCandidate) float64 {
+	free := 1.0
+	if c.Stats.TotalBytes > 0 {
+		free = float64(c.Stats.FreeBytes) / float64(c.Stats.TotalBytes)
+	}
+	return free / (1 + p.CreateWeight*c.RecentCreates + p.RepairWeight*float64(c.InflightRepairs))
+}
+
+func (p weightedPlacement) Place(n int, candidates []PlacementCandidate, usedRacks []string) []string {
+	ranked := make([]PlacementCandidate, len(candidates))
+	copy(ranked, candidates)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		si, sj := p.score(ranked[i]), p.score(ranked[j])
+		if si != sj {
+			return si > sj
+		}
+		return ranked[i].ID < ranked[j].ID
+	})
+
+	racks := make(map[string]bool, len(usedRacks)+n)
+	for _, r := range usedRacks {
+		racks[r] = true
+	}
+
+	out := make([]string, 0, n)
+	taken := make(map[string]bool, n)
+	for len(out) < n {
+		pick := -1
+		for i, c := range ranked {
+			if taken[c.ID] {
+				continue
+			}
+			if !racks[c.Rack] {
+				pick = i
+				break
+			}
+			if pick < 0 {
+				pick = i // best node in an already used rack, kept as fallback
+			}
+		}
+		if pick < 0 {
+			break
+		}
+		c := ranked[pick]
+		taken[c.ID] = true
+		racks[c.Rack] = true
+		out = append(out, c.ID)
+	}
+	return out
+}
+
+// recent creations decay with this half-life
+const createHalfLife = time.Minute
+
+// decayCreates brings cs.recentCreates up to now. Callers must hold mu.
+func (cs *ChunkServerInfo) decayCreates(now time.Time) {
+	if !cs.createsAt.IsZero() && cs.recentCreates > 0 {
+		dt := now.Sub(cs.createsAt)
+		cs.recentCreates *= math.Pow(0.5, float64(dt)/float64(createHalfLife))
+	}
+	cs.createsAt = now
+}
+
+// noteCreate records that a new replica was placed on cs. Callers must hold mu.
+func (cs *ChunkServerInfo) noteCreate(now time.Time) {
+	cs.decayCreates(now)
+	cs.recentCreates++
+}
+
+// placementCandidates lists alive chunkservers with room for a chunk,
+// skipping the IDs in exclude. Callers must hold mu.
+func placementCandidates(exclude map[string]bool) []PlacementCandidate {
+	now := time.Now()
+	var out []PlacementCandidate
+	for id, cs := range chunkServers {
+		if !cs.serving() || !cs.canHost(ChunkSize) || exclude[id] {
+			continue
+		}
+		cs.decayCreates(now)
+		out = append(out, PlacementCandidate{
+			ID:              id,
+			Rack:            cs.Rack,
+			Stats:           cs.NodeStats,
+			RecentCreates:   cs.recentCreates,
+			InflightRepairs: cs.inflightRepairs,
+		})
+	}
+	return out
+}
+
+// adjustRepairs changes the in-flight repair count of each node by delta.
+func adjustRepairs(delta int, ids ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, id := range ids {
+		if cs, ok := chunkServers[id]; ok {
+			cs.inflightRepairs += delta
+			if cs.inflightRepairs < 0 {
+				cs.inflightRepairs = 0
+			}
+		}
+	}
+}
+
+// replicaRacks returns the racks of the given chunkservers that are alive;
+// a dead replica spreads nothing. Callers must hold mu.
+func replicaRacks(ids []string) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if cs, ok := chunkServers[id]; ok && cs.Alive {
+			out = append(out, cs.Rack)
+		}
+	}
+	return out
+}
+
+// checkRackSpread flags chunks whose live replicas all sit in one rack while
+// the cluster has more than one rack to offer. Nodes without a rack label
+// could be anywhere, so a chunk with a replica on one is never flagged. The
+// chunks are evaluated from a copy so mu isn't held for the whole pass.
+func checkRackSpread() {
+	type chunkRacks struct {
+		id       string
+		replicas []string
+		flagged  bool
+	}
+
+	mu.Lock()
+	rackOf := make(map[string]string, len(chunkServers)) // alive nodes only
+	clusterRacks := make(map[string]bool)
+	for id, cs := range chunkServers {
+		if cs.Alive {
+			rackOf[id] = cs.Rack
+			if cs.Rack != "" {
+				clusterRacks[cs.Rack] = true
+			}
+		}
+	}
+	all := make([]chunkRacks, 0, len(chunks))
+	for cid, cm := range chunks {
+		all = append(all, chunkRacks{id: cid, replicas: slices.Clone(cm.Replicas), flagged: cm.SingleDomain})
+	}
+	mu.Unlock()
+
+	changed := make(map[string]bool)
+	for _, c := range all {
+		single := false
+		if len(clusterRacks) > 1 {
+			racks := make(map[string]bool)
+			live := 0
+			for _, r := range c.replicas {
+				if rack, ok := rackOf[r]; ok {
+					racks[rack] = true
+					live++
+				}
+			}
+			single = live > 1 && len(racks) == 1 && !racks[""]
+		}
+		if single != c.flagged {
+			changed[c.id] = single
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for cid, single := range changed {
+		cm, ok := chunks[cid]
+		if !ok {
+			continue
+		}
+		if single && !cm.SingleDomain {
+			log.Printf("master: chunk %s has all %d live replicas in one rack", cid, len(cm.Replicas))
+		}
+		cm.SingleDomain = single
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// capacity of every test node, in chunks
+const testCapacity = 200
+
+// testNode describes one chunkserver of a placement test cluster.
+type testNode struct {
+	id      string
+	rack    string
+	free    float64 // fraction of capacity free
+	creates float64 // recent creations before the test starts
+	repairs int     // repairs in flight for the whole test
+}
+
+// resetCluster replaces the master's state with nodes and points the op-log
+// at a temporary directory.
+func resetCluster(t *testing.T, nodes []testNode) {
+	t.Helper()
+	c := defaultConfig()
+	c.DataDir = t.TempDir()
+	applyConfig(c)
+
+	mu.Lock()
+	defer mu.Unlock()
+	chunkServers = make(map[string]*ChunkServerInfo)
+	files = make(map[string]*FileMeta)
+	chunks = make(map[string]*ChunkMeta)
+	dirs = make(map[string]bool)
+	for _, n := range nodes {
+		total := uint64(testCapacity * ChunkSize)
+		free := uint64(n.free*testCapacity) * uint64(ChunkSize)
+		chunkServers[n.id] = &ChunkServerInfo{
+			Addr:  n.id,
+			Rack:  n.rack,
+			Alive: true,
+			NodeStats: NodeStats{
+				TotalBytes: total,
+				FreeBytes:  free,
+				UsedBytes:  total - free,
+			},
+			recentCreates:   n.creates,
+			inflightRepairs: n.repairs,
+		}
+	}
+}
+
+// stored does what the next heartbeat would: node's capacity numbers now
+// include one more chunk.
+func stored(node string) {
+	cs := chunkServers[node]
+	cs.FreeBytes -= uint64(ChunkSize)
+	cs.UsedBytes += uint64(ChunkSize)
+}
+
+// spread checks how evenly counts are spread over the nodes in ids: the
+// busiest may hold at most maxRatio times what the least busy holds.
+func spread(t *testing.T, counts map[string]int, ids []string, maxRatio float64) {
+	t.Helper()
+	lo, hi := -1, 0
+	for _, id := range ids {
+		n := counts[id]
+		if lo < 0 || n < lo {
+			lo = n
+		}
+		hi = max(hi, n)
+	}
+	if lo == 0 {
+		t.Errorf("some node got no replicas: %v", counts)
+		return
+	}
+	if r := float64(hi) / float64(lo); r > maxRatio {
+		t.Errorf("max/min replicas = %d/%d = %.2f, want <= %.2f: %v", hi, lo, r, maxRatio, counts)
+	}
+}
+
+// freeSpread checks that the free fractions of all nodes end up within
+// maxSpread of each other.
+func freeSpread(t *testing.T, maxSpread float64) {
+	t.Helper()
+	lo, hi := 1.0, 0.0
+	for _, cs := range chunkServers {
+		if !cs.Alive {
+			continue
+		}
+		f := float64(cs.FreeBytes) / float64(cs.TotalBytes)
+		lo, hi = min(lo, f), max(hi, f)
+	}
+	if hi-lo > maxSpread {
+		t.Errorf("free fractions range from %.2f to %.2f, want within %.2f", lo, hi, maxSpread)
+	}
+}
+
+func nodeIDs(nodes []testNode) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = n.id
+	}
+	return out
+}
+
+func uniform(n int, rack func(i int) string) []testNode {
+	out := make([]testNode, n)
+	for i := range out {
+		out[i] = testNode{id: fmt.Sprintf("cs%d", i), rack: rack(i), free: 0.5}
+	}
+	return out
+}
+
+func noRack(int) string { return "" }
+
+type placementTest struct {
+	name        string
+	nodes       []testNode
+	replication int
+	chunks      int
+	even        []string // nodes that should get equal shares, nil for all, empty for none
+	maxRatio    float64  // bound on max/min replicas among them
+	fewer       []string // nodes that must get fewer than the average
+	byFree      bool     // nodes are listed emptiest first and must get no more than the one before
+	maxFree     float64  // bound on the final spread of free fractions, 0 to skip
+	racks       bool     // every chunk's replicas must be in distinct racks
+}
+
+var placementTests = []placementTest{
+	{
+		name:        "equal nodes",
+		nodes:       uniform(6, noRack),
+		replication: 3,
+		chunks:      60,
+		maxRatio:    1.1,
+		maxFree:     0.05,
+	},
+	{
+		name: "uneven free space",
+		nodes: []testNode{
+			{id: "cs0", free: 0.9}, {id: "cs1", free: 0.7}, {id: "cs2", free: 0.5},
+			{id: "cs3", free: 0.3}, {id: "cs4", free: 0.2}, {id: "cs5", free: 0.1},
+		},
+		// emptier nodes take more, so fill levels converge: free fractions
+		// 0.8 apart end up much closer
+		replication: 2,
+		chunks:      200,
+		even:        []string{},
+		fewer:       []string{"cs3", "cs4", "cs5"},
+		byFree:      true,
+		maxFree:     0.5,
+	},
+	{
+		name: "recent creations",
+		nodes: []testNode{
+			{id: "cs0", free: 0.5, creates: 20}, {id: "cs1", free: 0.5},
+			{id: "cs2", free: 0.5}, {id: "cs3", free: 0.5},
+		},
+		replication: 2,
+		chunks:      40,
+		even:        []string{"cs1", "cs2", "cs3"},
+		maxRatio:    1.1,
+		fewer:       []string{"cs0"},
+	},
+	{
+		name: "in-flight repairs",
+		nodes: []testNode{
+			{id: "cs0", free: 0.5, repairs: 4}, {id: "cs1", free: 0.5},
+			{id: "cs2", free: 0.5}, {id: "cs3", free: 0.5},
+		},
+		replication: 2,
+		chunks:      40,
+		even:        []string{"cs1", "cs2", "cs3"},
+		maxRatio:    1.1,
+		fewer:       []string{"cs0"},
+	},
+	{
+		name:        "rack labels",
+		nodes:       uniform(6, func(i int) string { return fmt.Sprintf("r%d", i%3) }),
+		replication: 3,
+		chunks:      60,
+		maxRatio:    1.1,
+		maxFree:     0.05,
+		racks:       true,
+	},
+}
+
+// check runs the table's assertions on the replicas placed on each node.
+func (tt placementTest) check(t *testing.T, counts map[string]int) {
+	t.Helper()
+	even := tt.even
+	if even == nil {
+		even = nodeIDs(tt.nodes)
+	}
+	if len(even) > 0 {
+		spread(t, counts, even, tt.maxRatio)
+	}
+	checkFewer(t, counts, tt.nodes, tt.fewer)
+	if tt.byFree {
+		for i := 1; i < len(tt.nodes); i++ {
+			a, b := tt.nodes[i-1].id, tt.nodes[i].id
+			if counts[b] > counts[a] {
+				t.Errorf("%s got %d replicas, more than emptier %s with %d", b, counts[b], a, counts[a])
+			}
+		}
+	}
+	if tt.maxFree > 0 {
+		freeSpread(t, tt.maxFree)
+	}
+}
+
+func checkFewer(t *testing.T, counts map[string]int, nodes []testNode, fewer []string) {
+	t.Helper()
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	avg := float64(total) / float64(len(nodes))
+	for _, id := range fewer {
+		if float64(counts[id]) >= avg {
+			t.Errorf("%s got %d replicas, want fewer than the average %.1f: %v", id, counts[id], avg, counts)
+		}
+	}
+}
+
+func checkRacks(t *testing.T, chunkID string, replicas []string) {
+	t.Helper()
+	seen := make(map[string]bool)
+	for _, r := range replicaRacks(replicas) {
+		if seen[r] {
+			t.Errorf("chunk %s has two replicas in rack %s: %v", chunkID, r, replicas)
+		}
+		seen[r] = true
+	}
+}
+
+func TestPlacementAllocate(t *testing.T) {
+	for _, tt := range placementTests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCluster(t, tt.nodes)
+			counts := make(map[string]int)
+			// one chunk per allocation, like a client writing a file chunk by chunk
+			for i := 0; i < tt.chunks; i++ {
+				resp, err := allocateChunks("f", ChunkSize, tt.replication)
+				if err != nil {
+					t.Fatal(err)
+				}
+				replicas := resp.Locations[0]
+				if len(replicas) != tt.replication {
+					t.Fatalf("chunk %d got %d replicas, want %d", i, len(replicas), tt.replication)
+				}
+				for _, r := range replicas {
+					counts[r]++
+					stored(r)
+				}
+				if tt.racks {
+					checkRacks(t, resp.ChunkIDs[0], replicas)
+				}
+			}
+			tt.check(t, counts)
+		})
+	}
+}
+
+func TestPlacementRepair(t *testing.T) {
+	for _, tt := range placementTests {
+		t.Run(tt.name, func(t *testing.T) {
+			// every chunk lost one replica on a dead node and needs a new one;
+			// its surviving replicas sit on the first nodes in turn
+			nodes := append([]testNode{{id: "dead", rack: "dead"}}, tt.nodes...)
+			resetCluster(t, nodes)
+			chunkServers["dead"].Alive = false
+			ids := nodeIDs(tt.nodes)
+			for i := 0; i < tt.chunks; i++ {
+				cid := fmt.Sprintf("c%d", i)
+				replicas := []string{"dead"}
+				for j := 0; j < tt.replication-1; j++ {
+					replicas = append(replicas, ids[(i+j)%len(ids)])
+				}
+				chunks[cid] = &ChunkMeta{ID: cid, FileName: "f", Index: i, Replicas: replicas}
+			}
+
+			counts := make(map[string]int)
+			for i := 0; i < tt.chunks; i++ {
+				cid := fmt.Sprintf("c%d", i)
+				_, target, err := pickRepair(cid, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				cm := chunks[cid]
+				if cm.replicaSet()[target] {
+					t.Fatalf("chunk %s: target %s already holds it", cid, target)
+				}
+				// what copyReplica records once the copy succeeded
+				cm.Replicas = append(removeReplica(cm.Replicas, "dead"), target)
+				chunkServers[target].noteCreate(time.Now())
+				counts[target]++
+				stored(target)
+				if tt.racks {
+					checkRacks(t, cid, cm.Replicas)
+				}
+			}
+			tt.check(t, counts)
+		})
+	}
+}
@@ -32,31 +32,40 @@ func getPrimaryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	resp, err := currentPrimary(req.ChunkID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// currentPrimary returns the lease holder of chunkID, with an empty primary
+// when no lease is valid.
+func currentPrimary(chunkID string) (primaryResp, error) {
 	mu.Lock()
-	cm, ok := chunks[req.ChunkID]
-	mu.Unlock()
+	defer mu.Unlock()
 
+	cm, ok := chunks[chunkID]
 	if !ok {
-		http.Error(w, "chunk not found", http.StatusNotFound)
-		return
+		return primaryResp{}, errorf(http.StatusNotFound, "chunk not found")
 	}
 
 	if cm.LeaseExpires != 0 && time.Now().Unix() < cm.LeaseExpires {
-		json.NewEncoder(w).Encode(primaryResp{
+		return primaryResp{
 			Primary:      cm.Primary,
 			LeaseSeconds: cm.LeaseExpires - time.Now().Unix(),
 			Replicas:     cm.Replicas,
 			Version:      cm.Version,
-		})
-		return
+		}, nil
 	}
 
-	json.NewEncoder(w).Encode(primaryResp{
+	return primaryResp{
 		Primary:      "",
 		LeaseSeconds: 0,
 		Replicas:     cm.Replicas,
 		Version:      cm.Version,
-	})
+	}, nil
 }
 
 // /assign_primary : master chooses a primary and grants a lease
@@ -72,19 +81,29 @@ func assignPrimaryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	resp, err := assignPrimary(req.ChunkID, req.Preferred)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// assignPrimary grants a new lease on chunkID, preferring the replica preferred.
+func assignPrimary(chunkID, preferred string) (primaryResp, error) {
 	mu.Lock()
-	cm, ok := chunks[req.ChunkID]
+	cm, ok := chunks[chunkID]
 	if !ok {
 		mu.Unlock()
-		http.Error(w, "chunk not found", http.StatusNotFound)
-		return
+		return primaryResp{}, errorf(http.StatusNotFound, "chunk not found")
 	}
 
-	// pick candidate primary: prefer requested, else first alive replica
+	// pick candidate primary: prefer requested, else first alive replica;
+	// draining nodes never get a lease
 	chosen := ""
 	for _, raddr := range cm.Replicas {
-		if req.Preferred != "" && raddr == req.Preferred {
-			if cs, ok := chunkServers[raddr]; ok && cs.Alive {
+		if preferred != "" && raddr == preferred {
+			if cs, ok := chunkServers[raddr]; ok && cs.serving() {
 				chosen = raddr
 				break
 			}
@@ -93,7 +112,7 @@ func assignPrimaryHandler(w http.ResponseWriter, r *http.Request) {
 
 	if chosen == "" {
 		for _, raddr := range cm.Replicas {
-			if cs, ok := chunkServers[raddr]; ok && cs.Alive {
+			if cs, ok := chunkServers[raddr]; ok && cs.serving() {
 				chosen = raddr
 				break
 			}
@@ -102,31 +121,28 @@ func assignPrimaryHandler(w http.ResponseWriter, r *http.Request) {
 
 	if chosen == "" {
 		mu.Unlock()
-		http.Error(w, "no alive replica to assign primary", http.StatusServiceUnavailable)
-		return
+		return primaryResp{}, errorf(http.StatusServiceUnavailable, "no alive replica to assign primary")
 	}
 
 	// grant lease
-	leaseSec := int64(10)
+	leaseSec := leaseSeconds
 	cm.Primary = chosen
 	cm.LeaseExpires = time.Now().Unix() + leaseSec
-	cm.Version += 1
-	chunks[req.ChunkID] = cm
-	mu.Unlock()
-
+	chunks[chunkID] = cm
 	appendOpLog("assign_primary", map[string]any{
-		"chunk_id": req.ChunkID,
+		"chunk_id": chunkID,
 		"primary":  chosen,
 		"version":  cm.Version,
 	})
+	mu.Unlock()
 
-	log.Printf("master:  assigned primary %s for chunk %s lease %ds", chosen, req.ChunkID, leaseSec)
-	json.NewEncoder(w).Encode(primaryResp{
+	log.Printf("master:  assigned primary %s for chunk %s lease %ds", chosen, chunkID, leaseSec)
+	return primaryResp{
 		Primary:      chosen,
 		LeaseSeconds: leaseSec,
 		Replicas:     cm.Replicas,
 		Version:      cm.Version,
-	})
+	}, nil
 }
 
 // /renew_lease : primary calls this periodically to renew its lease
@@ -151,23 +167,32 @@ func renewLeaseHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	resp, err := renewLease(req.ChunkID, req.Primary)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// renewLease extends primary's lease on chunkID; Ok is false if primary no
+// longer holds it.
+func renewLease(chunkID, primary string) (renewResp, error) {
 	mu.Lock()
-	cm, ok := chunks[req.ChunkID]
+	cm, ok := chunks[chunkID]
 	if !ok {
 		mu.Unlock()
-		http.Error(w, "chunk not found", http.StatusNotFound)
-		return
+		return renewResp{}, errorf(http.StatusNotFound, "chunk not found")
 	}
-	if cm.Primary != req.Primary {
+	if cm.Primary != primary {
 		mu.Unlock()
-		json.NewEncoder(w).Encode(renewResp{Ok: false, LeaseSeconds: 0})
-		return
+		return renewResp{Ok: false, LeaseSeconds: 0}, nil
 	}
 	// renew
-	leaseSec := int64(10)
+	leaseSec := leaseSeconds
 	cm.LeaseExpires = time.Now().Unix() + leaseSec
-	chunks[req.ChunkID] = cm
+	chunks[chunkID] = cm
 	mu.Unlock()
 
-	json.NewEncoder(w).Encode(renewResp{Ok: true, LeaseSeconds: leaseSec})
+	return renewResp{Ok: true, LeaseSeconds: leaseSec}, nil
 }
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// the rebalancer gives up after this many failed moves in a row
+const rebalanceMaxFailures = 5
+
+// rebalanceMove copies one replica of a chunk from a node above the average
+// utilization to one below it.
+type rebalanceMove struct {
+	ChunkID string `json:"chunk_id"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Bytes   int64  `json:"bytes"`   // the chunk's committed length when planned
+	Version uint64 `json:"version"` // the chunk's version when planned
+}
+
+// rebalancer evens out disk usage across chunkservers. Only one run is active
+// at a time; it ends when the cluster is within the threshold, when stopped,
+// or after repeated failures.
+type rebalancer struct {
+	mu        sync.Mutex
+	running   bool
+	stop      chan struct{}
+	state     string // idle, running, balanced, stopped, failed
+	threshold float64
+	bandwidth int64
+	started   time.Time
+	finished  time.Time
+	moves     int
+	failures  int
+	bytes     int64
+	current   *rebalanceMove
+	lastError string
+	moving    map[string]bool // chunkID -> move in flight
+}
+
+var rebalance = &rebalancer{state: "idle", moving: make(map[string]bool)}
+
+type rebalanceStartRequest struct {
+	Threshold float64 `json:"threshold,omitempty"`
+	Bandwidth int64   `json:"bandwidth,omitempty"` // bytes per second
+}
+
+// start begins a rebalancing run unless one is already active.
+func (rb *rebalancer) start(threshold float64, bandwidth int64) error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	if rb.running {
+		return fmt.Errorf("rebalancer already running")
+	}
+	rb.running = true
+	rb.stop = make(chan struct{})
+	rb.state = "running"
+	rb.threshold = threshold
+	rb.bandwidth = bandwidth
+	rb.started = time.Now()
+	rb.finished = time.Time{}
+	rb.moves, rb.failures, rb.bytes = 0, 0, 0
+	rb.current = nil
+	rb.lastError = ""
+	go rb.loop(rb.stop, threshold, bandwidth)
+	log.Printf("master: rebalancer started (threshold %.2f, %d bytes/s)", threshold, bandwidth)
+	return nil
+}
+
+// halt asks the active run to stop after its current move.
+func (rb *rebalancer) halt() bool {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	if !rb.running || rb.stop == nil {
+		return false
+	}
+	close(rb.stop)
+	rb.stop = nil
+	return true
+}
+
+func (rb *rebalancer) finish(state string) {
+	rb.mu.Lock()
+	rb.running = false
+	rb.stop = nil
+	rb.state = state
+	rb.current = nil
+	rb.finished = time.Now()
+	moves := rb.moves
+	rb.mu.Unlock()
+	log.Printf("master: rebalancer %s after %d moves", state, moves)
+}
+
+// isMoving reports whether a rebalance move of chunkID is in flight. The
+// replication scanner leaves such chunks alone: they are briefly one replica
+// over target until the old copy is dropped.
+func (rb *rebalancer) isMoving(chunkID string) bool {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.moving[chunkID]
+}
+
+func (rb *rebalancer) loop(stop chan struct{}, threshold float64, bandwidth int64) {
+	// chunks that were moved or failed to move are left alone for the rest of the run
+	skip := make(map[string]bool)
+	failedInRow := 0
+
+	for {
+		select {
+		case <-stop:
+			rb.finish("stopped")
+			return
+		default:
+		}
+
+		mv, ok := planMove(threshold, skip)
+		if !ok {
+			rb.finish("balanced")
+			return
+		}
+
+		rb.mu.Lock()
+		rb.current = &mv
+		rb.moving[mv.ChunkID] = true
+		rb.mu.Unlock()
+
+		began := time.Now()
+		err := moveReplica(mv)
+
+		rb.mu.Lock()
+		delete(rb.moving, mv.ChunkID)
+		rb.current = nil
+		if err != nil {
+			rb.failures++
+			rb.lastError = err.Error()
+		} else {
+			rb.moves++
+			rb.bytes += mv.Bytes
+		}
+		rb.mu.Unlock()
+
+		skip[mv.ChunkID] = true
+		if err != nil {
+			log.Printf("master: rebalance of %s (%s -> %s) failed: %v", mv.ChunkID, mv.From, mv.To, err)
+			if failedInRow++; failedInRow >= rebalanceMaxFailures {
+				rb.finish("failed")
+				return
+			}
+		} else {
+			failedInRow = 0
+		}
+
+		// each move is charged its chunk's length against the bandwidth budget
+		wait := time.Duration(float64(mv.Bytes)/float64(bandwidth)*float64(time.Second)) - time.Since(began)
+		if wait > 0 {
+			select {
+			case <-stop:
+				rb.finish("stopped")
+				return
+			case <-time.After(wait):
+			}
+		}
+	}
+}
+
+// planMove picks the next replica to move: from the fullest node over the
+// average to the emptiest node under it, choosing a chunk that is healthy,
+// not being written and whose rack spread doesn't shrink.
+func planMove(threshold float64, skip map[string]bool) (rebalanceMove, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	type nodeUtil struct {
+		id   string
+		util float64
+		cs   *ChunkServerInfo
+	}
+	var nodes []nodeUtil
+	total := 0.0
+	for id, cs := range chunkServers {
+		if !cs.serving() || cs.Conflict != "" || cs.TotalBytes == 0 {
+			continue
+		}
+		u := utilization(cs)
+		nodes = append(nodes, nodeUtil{id, u, cs})
+		total += u
+	}
+	if len(nodes) < 2 {
+		return rebalanceMove{}, false
+	}
+	avg := total / float64(len(nodes))
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].util > nodes[j].util })
+
+	for _, src := range nodes {
+		if src.util <= avg {
+			break
+		}
+		for i := len(nodes) - 1; i >= 0; i-- {
+			dst := nodes[i]
+			if dst.util >= avg {
+				break
+			}
+			// at least one side must be outside the threshold
+			if src.util <= avg+threshold && dst.util >= avg-threshold {
+				continue
+			}
+			// a move that would leave dst fuller than src only swaps the
+			// imbalance and makes the next pass move it back, so the chunk
+			// may be at most this long
+			srcTotal, dstTotal := float64(src.cs.TotalBytes), float64(dst.cs.TotalBytes)
+			maxLen := int64((src.util - dst.util) / (1/srcTotal + 1/dstTotal))
+			maxLen = min(maxLen, int64(dst.cs.FreeBytes))
+			if cid, length, ok := movableChunk(src.id, dst.id, maxLen, skip); ok {
+				return rebalanceMove{ChunkID: cid, From: src.id, To: dst.id, Bytes: length, Version: chunks[cid].Version}, true
+			}
+		}
+	}
+	return rebalanceMove{}, false
+}
+
+// movableChunk finds a chunk on from, at most maxLen bytes long, that can
+// move to to, and returns it with its length. Callers must hold mu.
+func movableChunk(from, to string, maxLen int64, skip map[string]bool) (string, int64, bool) {
+	ids := make([]string, 0)
+	for cid, cm := range chunks {
+		if skip[cid] || cm.LeaseValid() {
+			continue
+		}
+		// moving a chunk that was never written frees nothing
+		if cm.Length == 0 || cm.Length > maxLen {
+			continue
+		}
+		has := cm.replicaSet()
+		if !has[from] || has[to] {
+			continue
+		}
+		if aliveReplicaCount(cm) != chunkReplication(cm) {
+			continue // the repair queue and scanner own this chunk
+		}
+		after := make([]string, 0, len(cm.Replicas))
+		for _, r := range cm.Replicas {
+			if r != from {
+				after = append(after, r)
+			}
+		}
+		after = append(after, to)
+		if distinct(replicaRacks(after)) < distinct(replicaRacks(cm.Replicas)) {
+			continue
+		}
+		ids = append(ids, cid)
+	}
+	if len(ids) == 0 {
+		return "", 0, false
+	}
+	sort.Strings(ids)
+	return ids[0], chunks[ids[0]].Length, true
+}
+
+// utilization is the share of a node's capacity taken by chunk data. Other
+// files on the same disk don't count, so nodes sharing a filesystem still
+// balance by what they hold. Callers must hold mu.
+func utilization(cs *ChunkServerInfo) float64 {
+	return float64(cs.UsedBytes) / float64(cs.TotalBytes)
+}
+
+func distinct(xs []string) int {
+	seen := make(map[string]bool, len(xs))
+	for _, x := range xs {
+		seen[x] = true
+	}
+	return len(seen)
+}
+
+// moveReplica copies mv.ChunkID to mv.To, checks that mv.To now reports the
+// chunk at the planned version, and only then drops the replica on mv.From.
+// If the chunk was written or leased meanwhile the new copy may be behind, so
+// it is deleted again and mv.From keeps its replica.
+func moveReplica(mv rebalanceMove) error {
+	if err := copyReplica(mv.ChunkID, mv.From, mv.To, ""); err != nil {
+		return fmt.Errorf("copy: %w", err)
+	}
+
+	report, err := fetchChunkReport(mv.To)
+	if err != nil {
+		return fmt.Errorf("confirm copy on %s: %w", mv.To, err)
+	}
+	if v, ok := report.Versions[mv.ChunkID]; !ok || v != mv.Version {
+		return undoMove(mv, fmt.Errorf("%s does not report chunk %s at version %d after copy", mv.To, mv.ChunkID, mv.Version))
+	}
+
+	mu.Lock()
+	cm, ok := chunks[mv.ChunkID]
+	if !ok {
+		mu.Unlock()
+		return fmt.Errorf("chunk %s removed during move", mv.ChunkID)
+	}
+	// a write may have landed, or be starting, through any primary while
+	// copying; the old replica stays until the scanner sorts it out
+	if cm.Version != mv.Version || cm.LeaseValid() {
+		mu.Unlock()
+		return undoMove(mv, fmt.Errorf("chunk %s was written or leased during the move", mv.ChunkID))
+	}
+	mu.Unlock()
+
+	if err := removeReplicaFrom(mv.ChunkID, mv.From, false); err != nil {
+		return fmt.Errorf("drop old replica: %w", err)
+	}
+
+	// heartbeats report the real numbers a few seconds later; until then
+	// account for the move so the next plan doesn't overshoot
+	mu.Lock()
+	if cs, ok := chunkServers[mv.From]; ok {
+		cs.UsedBytes -= min(cs.UsedBytes, uint64(mv.Bytes))
+	}
+	if cs, ok := chunkServers[mv.To]; ok {
+		cs.UsedBytes += uint64(mv.Bytes)
+	}
+	mu.Unlock()
+
+	log.Printf("master: rebalanced chunk %s from %s to %s", mv.ChunkID, mv.From, mv.To)
+	return nil
+}
+
+// undoMove deletes the copy a failed move left on mv.To and returns err.
+func undoMove(mv rebalanceMove, err error) error {
+	if rerr := removeReplicaFrom(mv.ChunkID, mv.To, false); rerr != nil {
+		return fmt.Errorf("%w; removing the new copy: %v", err, rerr)
+	}
+	return err
+}
+
+type rebalanceStatus struct {
+	State        string             `json:"state"`
+	Running      bool               `json:"running"`
+	Threshold    float64            `json:"threshold,omitempty"`
+	Bandwidth    int64              `json:"bandwidth,omitempty"`
+	StartedUnix  int64              `json:"started_unix,omitempty"`
+	FinishedUnix int64              `json:"finished_unix,omitempty"`
+	Moves        int                `json:"moves"`
+	Failures     int                `json:"failures"`
+	BytesMoved   int64              `json:"bytes_moved"`
+	Current      *rebalanceMove     `json:"current,omitempty"`
+	LastError    string             `json:"last_error,omitempty"`
+	AverageUtil  float64            `json:"average_utilization"`
+	NodeUtil     map[string]float64 `json:"node_utilization"`
+}
+
+func (rb *rebalancer) status() rebalanceStatus {
+	rb.mu.Lock()
+	st := rebalanceStatus{
+		State:      rb.state,
+		Running:    rb.running,
+		Threshold:  rb.threshold,
+		Bandwidth:  rb.bandwidth,
+		Moves:      rb.moves,
+		Failures:   rb.failures,
+		BytesMoved: rb.bytes,
+		LastError:  rb.lastError,
+		NodeUtil:   make(map[string]float64),
+	}
+	if !rb.started.IsZero() {
+		st.StartedUnix = rb.started.Unix()
+	}
+	if !rb.finished.IsZero() {
+		st.FinishedUnix = rb.finished.Unix()
+	}
+	if rb.current != nil {
+		cur := *rb.current
+		st.Current = &cur
+	}
+	rb.mu.Unlock()
+
+	mu.Lock()
+	total := 0.0
+	for id, cs := range chunkServers {
+		if !cs.Alive || cs.TotalBytes == 0 {
+			continue
+		}
+		u := utilization(cs)
+		st.NodeUtil[id] = u
+		total += u
+	}
+	mu.Unlock()
+	if len(st.NodeUtil) > 0 {
+		st.AverageUtil = total / float64(len(st.NodeUtil))
+	}
+	return st
+}
+
+// /rebalance/start : begin moving replicas from full nodes to empty ones
+func rebalanceStartHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	req := rebalanceStartRequest{
+		Threshold: cfg.RebalanceThreshold,
+		Bandwidth: cfg.RebalanceBandwidth,
+	}
+	// the body is optional; an empty one keeps the configured defaults
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.Threshold <= 0 || req.Threshold >= 1 || req.Bandwidth <= 0 {
+		http.Error(w, "threshold must be in (0, 1) and bandwidth positive", http.StatusBadRequest)
+		return
+	}
+
+	if err := rebalance.start(req.Threshold, req.Bandwidth); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rebalance.status())
+}
+
+// /rebalance/stop : stop the running rebalancer after its current move
+func rebalanceStopHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !rebalance.halt() {
+		http.Error(w, "rebalancer not running", http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rebalance.status())
+}
+
+// /rebalance/status : progress of the current or last run and node utilization
+func rebalanceStatusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rebalance.status())
+}
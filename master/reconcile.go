@@ -0,0 +1,300 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+)
+
+type chunkReport struct {
+	UUID     string            `json:"uuid"`
+	Chunks   []string          `json:"chunks"`
+	Versions map[string]uint64 `json:"versions"`
+}
+
+var reconciling = make(map[string]bool) // chunkserver ID -> reconciliation in flight, guarded by mu
+
+// reconcileNode pulls a full chunk report from a chunkserver and brings the
+// replica lists in line with what it actually stores: replicas it no longer
+// has are dropped (and repaired), chunks it reports are recorded as replicas
+// if they are at the current version and deleted from it if they are stale.
+func reconcileNode(id, reason string) {
+	mu.Lock()
+	if reconciling[id] {
+		mu.Unlock()
+		return
+	}
+	reconciling[id] = true
+	mu.Unlock()
+
+	defer func() {
+		mu.Lock()
+		delete(reconciling, id)
+		mu.Unlock()
+	}()
+
+	log.Printf("master: reconciling chunkserver %s (%s)", id, reason)
+
+	report, err := fetchChunkReport(id)
+	if err != nil {
+		log.Printf("master: chunk report from %s failed: %v", id, err)
+		return
+	}
+
+	held := make(map[string]bool, len(report.Chunks))
+	for _, cid := range report.Chunks {
+		held[cid] = true
+	}
+
+	var added, removed, stale, underReplicated, overReplicated []string
+	orphans := 0
+
+	mu.Lock()
+	if cs, ok := chunkServers[id]; ok && cs.UUID != "" && report.UUID != "" && cs.UUID != report.UUID {
+		// the address now answers with a different identity; wait for it to register
+		expected := cs.UUID
+		mu.Unlock()
+		log.Printf("master: chunk report from %s has uuid %s, expected %s; skipping", id, report.UUID, expected)
+		return
+	}
+	for cid, cm := range chunks {
+		listed := false
+		for _, r := range cm.Replicas {
+			if r == id {
+				listed = true
+				break
+			}
+		}
+		staleCopy := held[cid] && !currentCopy(cm, report.Versions[cid])
+		switch {
+		case listed && (!held[cid] || staleCopy && !cm.LeaseValid()):
+			// a stale copy missed writes while the node was away; under a
+			// valid lease a write may have landed after the report was taken
+			if staleCopy {
+				stale = append(stale, cid)
+			}
+			cm.Replicas = removeReplica(cm.Replicas, id)
+			if cm.Primary == id {
+				cm.Primary = ""
+				cm.LeaseExpires = 0
+			}
+			removed = append(removed, cid)
+			if aliveReplicaCount(cm) < chunkReplication(cm) {
+				underReplicated = append(underReplicated, cid)
+			}
+		case !listed && staleCopy:
+			stale = append(stale, cid)
+		case !listed && held[cid]:
+			cm.Replicas = append(cm.Replicas, id)
+			added = append(added, cid)
+			if aliveReplicaCount(cm) > chunkReplication(cm) {
+				overReplicated = append(overReplicated, cid)
+			}
+		}
+	}
+	for cid := range held {
+		if _, ok := chunks[cid]; !ok {
+			orphans++
+		}
+	}
+	if len(added) > 0 || len(removed) > 0 {
+		appendOpLog("reconcile", map[string]any{
+			"node":    id,
+			"added":   added,
+			"removed": removed,
+		})
+	}
+	mu.Unlock()
+
+	log.Printf("master: reconciled %s: %d chunks reported, %d added, %d lost, %d stale, %d unknown",
+		id, len(report.Chunks), len(added), len(removed), len(stale), orphans)
+
+	if len(stale) > 0 {
+		go deleteStale(id, stale)
+	}
+	for _, cid := range underReplicated {
+		repairs.enqueue(cid, id)
+	}
+	for _, cid := range overReplicated {
+		go ensureReplication(cid)
+	}
+}
+
+type lostChunksRequest struct {
+	Addr   string   `json:"addr"`
+	UUID   string   `json:"uuid"`
+	Chunks []string `json:"chunks"`
+}
+
+// /report_lost : a chunkserver tells us chunks it can no longer serve (e.g. a failed disk)
+func reportLostHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	var req lostChunksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if err := reportLost(req); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Write([]byte(`{"status":"ok"}`))
+}
+
+// reportLost drops req.Addr from the replica lists of the chunks it lost and
+// queues repairs for those left under-replicated.
+func reportLost(req lostChunksRequest) error {
+	if req.Addr == "" {
+		return errorf(http.StatusBadRequest, "addr required")
+	}
+
+	var removed, underReplicated []string
+	mu.Lock()
+	if cs, ok := chunkServers[req.Addr]; ok && identityMismatch(cs, req.UUID, "") {
+		mu.Unlock()
+		return errorf(http.StatusConflict, "address registered by another chunkserver")
+	}
+	for _, cid := range req.Chunks {
+		cm, ok := chunks[cid]
+		if !ok {
+			continue
+		}
+		before := len(cm.Replicas)
+		cm.Replicas = removeReplica(cm.Replicas, req.Addr)
+		if len(cm.Replicas) == before {
+			continue
+		}
+		if cm.Primary == req.Addr {
+			cm.Primary = ""
+			cm.LeaseExpires = 0
+		}
+		removed = append(removed, cid)
+		if aliveReplicaCount(cm) < chunkReplication(cm) {
+			underReplicated = append(underReplicated, cid)
+		}
+	}
+	if len(removed) > 0 {
+		appendOpLog("reconcile", map[string]any{
+			"node":    req.Addr,
+			"added":   []string{},
+			"removed": removed,
+		})
+	}
+	mu.Unlock()
+
+	log.Printf("master: %s reported %d lost chunks, %d replicas dropped", req.Addr, len(req.Chunks), len(removed))
+
+	for _, cid := range underReplicated {
+		repairs.enqueue(cid, req.Addr)
+	}
+	return nil
+}
+
+func fetchChunkReport(id string) (*chunkReport, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(fmt.Sprintf("http://%s/chunk_report", id))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bad status: %s", resp.Status)
+	}
+	var report chunkReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("invalid chunk report: %w", err)
+	}
+	return &report, nil
+}
+
+// currentCopy reports whether a copy of cm at version v has every committed
+// write. Version 0 is a copy stored before chunkservers kept versions, which
+// is trusted. Callers must hold mu.
+func currentCopy(cm *ChunkMeta, v uint64) bool {
+	return v == 0 || v >= cm.Version
+}
+
+// dropStaleLocked removes node from the replica lists of the chunks whose
+// copy there, at the version in versions, is behind. Chunks under a valid
+// lease are left alone: a write may be committing. It returns the stale
+// chunks and those now short of their target. Callers must hold mu.
+func dropStaleLocked(node string, versions map[string]uint64) (stale, underReplicated []string) {
+	for cid, v := range versions {
+		cm, ok := chunks[cid]
+		if !ok || currentCopy(cm, v) || cm.LeaseValid() || !slices.Contains(cm.Replicas, node) {
+			continue
+		}
+		cm.Replicas = removeReplica(cm.Replicas, node)
+		if cm.Primary == node {
+			cm.Primary = ""
+			cm.LeaseExpires = 0
+		}
+		stale = append(stale, cid)
+		if aliveReplicaCount(cm) < chunkReplication(cm) {
+			underReplicated = append(underReplicated, cid)
+		}
+	}
+	if len(stale) > 0 {
+		appendOpLog("reconcile", map[string]any{
+			"node":    node,
+			"added":   []string{},
+			"removed": stale,
+		})
+	}
+	return stale, underReplicated
+}
+
+// deleteStale tells node to delete its stale copies of chunkIDs.
+func deleteStale(node string, chunkIDs []string) {
+	for _, cid := range chunkIDs {
+		if err := deleteChunkOn(node, cid); err != nil {
+			log.Printf("master: deleting stale copy of %s: %v", cid, err)
+			continue
+		}
+		log.Printf("master: deleted stale copy of %s on %s", cid, node)
+	}
+}
+
+func removeReplica(replicas []string, id string) []string {
+	out := make([]string, 0, len(replicas))
+	for _, r := range replicas {
+		if r != id {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// aliveReplicaCount counts the replicas of cm that count toward its target:
+// those on alive chunkservers that aren't being decommissioned, plus those on
+// nodes down for maintenance. Callers must hold mu.
+func aliveReplicaCount(cm *ChunkMeta) int {
+	n := 0
+	for _, r := range cm.Replicas {
+		if cs, ok := chunkServers[r]; ok && cs.countsTowardTarget() {
+			n++
+		}
+	}
+	return n
+}
+
+// readableReplicaCount counts replicas of cm on alive chunkservers, draining
+// ones included, i.e. the copies repair can still read from. Callers must hold mu.
+func readableReplicaCount(cm *ChunkMeta) int {
+	n := 0
+	for _, r := range cm.Replicas {
+		if cs, ok := chunkServers[r]; ok && cs.Alive {
+			n++
+		}
+	}
+	return n
+}
@@ -5,10 +5,11 @@ import (
 	"io"
 	"log"
 	"os"
+	"slices"
 )
 
 func loadCheckpoint() {
-	b, err := os.ReadFile("checkpoint.json")
+	b, err := os.ReadFile(checkpointPath)
 	if err != nil {
 		log.Printf("master: no checkpoint found, starting fresh")
 		return
@@ -25,13 +26,16 @@ func loadCheckpoint() {
 	files = cp.Files
 	chunks = cp.Chunks
 	chunkServers = cp.ChunkServers
+	if cp.Dirs != nil {
+		dirs = cp.Dirs
+	}
 	mu.Unlock()
 
 	log.Printf("master: checkpoint loaded (%d files, %d chunks)", len(files), len(chunks))
 }
 
 func replayOpLog() {
-	f, err := os.Open("oplog.jsonl")
+	f, err := os.Open(opLogPath)
 	if err != nil {
 		log.Printf("master: no op-log found")
 		return
@@ -67,17 +71,31 @@ func applyLogEntry(e map[string]any) {
 			return
 		}
 		fileName, _ := m["file"].(string)
-		chunksIface, _ := m["chunks"].([]any)
+		repl, _ := m["replication"].(float64)
 
+		// chunks the checkpoint already has under another name were renamed
+		// after this entry; replicas come back with the chunk reports
 		mu.Lock()
-		if _, exists := files[fileName]; !exists {
-			fm := &FileMeta{Name: fileName}
-			for _, ci := range chunksIface {
-				if csid, ok := ci.(string); ok {
-					fm.Chunks = append(fm.Chunks, csid)
-				}
+		ids := stringList(m["chunks"])
+		if _, exists := files[fileName]; !exists && len(ids) == 0 {
+			// an empty file
+			files[fileName] = &FileMeta{Name: fileName, Replication: int(repl)}
+		}
+		for _, csid := range ids {
+			if cm, ok := chunks[csid]; ok && cm.FileName != fileName {
+				continue
+			}
+			fm, exists := files[fileName]
+			if !exists {
+				fm = &FileMeta{Name: fileName, Replication: int(repl)}
+				files[fileName] = fm
+			}
+			if !slices.Contains(fm.Chunks, csid) {
+				fm.Chunks = append(fm.Chunks, csid)
+			}
+			if _, ok := chunks[csid]; !ok {
+				chunks[csid] = &ChunkMeta{ID: csid, FileName: fileName, Index: slices.Index(fm.Chunks, csid)}
 			}
-			files[fileName] = fm
 		}
 		mu.Unlock()
 
@@ -95,21 +113,47 @@ func applyLogEntry(e map[string]any) {
 		mu.Lock()
 		if cm, ok := chunks[cid]; ok {
 			cm.Primary = p
-			cm.Version = ver
+			cm.Version = max(cm.Version, ver)
 			chunks[cid] = cm
 		}
 		mu.Unlock()
 
+	case "chunk_length":
+		// payload: {"chunk_id": string, "length": number, "version": number, "file": string, "file_length": number}
+		m, ok := payload.(map[string]any)
+		if !ok {
+			return
+		}
+		cid, _ := m["chunk_id"].(string)
+		length, _ := m["length"].(float64)
+		ver, _ := m["version"].(float64)
+		fileName, _ := m["file"].(string)
+		fileLength, _ := m["file_length"].(float64)
+		mu.Lock()
+		if cm, ok := chunks[cid]; ok {
+			cm.Length = int64(length)
+			cm.Version = max(cm.Version, uint64(ver))
+		}
+		if fm, ok := files[fileName]; ok {
+			fm.Length = int64(fileLength)
+		}
+		mu.Unlock()
+
 	case "repair":
-		// payload: {"chunk_id": string, "new_replica": string}
+		// payload: {"chunk_id": string, "new_replica": string, "removed": string}
 		m, ok := payload.(map[string]any)
 		if !ok {
 			return
 		}
 		cid, _ := m["chunk_id"].(string)
 		newr, _ := m["new_replica"].(string)
+		removed, _ := m["removed"].(string)
 		mu.Lock()
 		if cm, ok := chunks[cid]; ok {
+			// the dead replica the new copy replaced, as copyReplica dropped it
+			if removed != "" && removed != newr {
+				cm.Replicas = removeReplica(cm.Replicas, removed)
+			}
 			// idempotent add if missing
 			found := false
 			for _, r := range cm.Replicas {
@@ -124,5 +168,154 @@ func applyLogEntry(e map[string]any) {
 			}
 		}
 		mu.Unlock()
+
+	case "set_replication":
+		// payload: {"file": string, "replication": number}
+		m, ok := payload.(map[string]any)
+		if !ok {
+			return
+		}
+		fileName, _ := m["file"].(string)
+		repl, _ := m["replication"].(float64)
+		mu.Lock()
+		if fm, ok := files[fileName]; ok {
+			fm.Replication = int(repl)
+		}
+		mu.Unlock()
+
+	case "drop_replica":
+		// payload: {"chunk_id": string, "replica": string}
+		m, ok := payload.(map[string]any)
+		if !ok {
+			return
+		}
+		cid, _ := m["chunk_id"].(string)
+		replica, _ := m["replica"].(string)
+		mu.Lock()
+		if cm, ok := chunks[cid]; ok {
+			cm.Replicas = removeReplica(cm.Replicas, replica)
+		}
+		mu.Unlock()
+
+	case "decommission":
+		// payload: {"node": string, "draining": bool}
+		m, ok := payload.(map[string]any)
+		if !ok {
+			return
+		}
+		node, _ := m["node"].(string)
+		draining, _ := m["draining"].(bool)
+		mu.Lock()
+		cs, ok := chunkServers[node]
+		if !ok {
+			// not in the checkpoint; keep the flag until the node registers
+			cs = &ChunkServerInfo{Addr: node}
+			chunkServers[node] = cs
+		}
+		cs.Draining = draining
+		mu.Unlock()
+
+	case "maintenance":
+		// payload: {"node": string, "until": unix seconds, 0 when ended}
+		m, ok := payload.(map[string]any)
+		if !ok {
+			return
+		}
+		node, _ := m["node"].(string)
+		until, _ := m["until"].(float64)
+		mu.Lock()
+		cs, ok := chunkServers[node]
+		if !ok {
+			// not in the checkpoint; keep the window until the node registers
+			cs = &ChunkServerInfo{Addr: node}
+			chunkServers[node] = cs
+		}
+		cs.MaintenanceUntil = int64(until)
+		mu.Unlock()
+
+	case "mkdir":
+		// payload: {"dirs": []string}
+		m, ok := payload.(map[string]any)
+		if !ok {
+			return
+		}
+		made, _ := m["dirs"].([]any)
+		mu.Lock()
+		for _, di := range made {
+			if d, ok := di.(string); ok {
+				dirs[d] = true
+			}
+		}
+		mu.Unlock()
+
+	case "delete":
+		// payload: {"files": []string, "dirs": []string}
+		m, ok := payload.(map[string]any)
+		if !ok {
+			return
+		}
+		mu.Lock()
+		removeLocked(stringList(m["files"]), stringList(m["dirs"]))
+		mu.Unlock()
+
+	case "rename":
+		// payload: {"from": string, "to": string}
+		m, ok := payload.(map[string]any)
+		if !ok {
+			return
+		}
+		from, _ := m["from"].(string)
+		to, _ := m["to"].(string)
+		mu.Lock()
+		renameLocked(from, to)
+		mu.Unlock()
+
+	case "reconcile":
+		// payload: {"node": string, "added": []string, "removed": []string}
+		m, ok := payload.(map[string]any)
+		if !ok {
+			return
+		}
+		node, _ := m["node"].(string)
+		added, _ := m["added"].([]any)
+		removed, _ := m["removed"].([]any)
+		mu.Lock()
+		for _, ci := range removed {
+			if cid, ok := ci.(string); ok {
+				if cm, ok := chunks[cid]; ok {
+					cm.Replicas = removeReplica(cm.Replicas, node)
+				}
+			}
+		}
+		for _, ci := range added {
+			cid, _ := ci.(string)
+			cm, ok := chunks[cid]
+			if !ok {
+				continue
+			}
+			found := false
+			for _, r := range cm.Replicas {
+				if r == node {
+					found = true
+					break
+				}
+			}
+			if !found {
+				cm.Replicas = append(cm.Replicas, node)
+			}
+		}
+		mu.Unlock()
+	}
+}
+
+// stringList returns the strings in a decoded JSON array.
+func stringList(v any) []string {
+	items, _ := v.([]any)
+	out := make([]string, 0, len(items))
+	for _, it := range items {
+		if s, ok := it.(string); ok {
+			out = append(out, s)
+		}
 	}
+	return out
 }
@@ -39,71 +39,74 @@ func repairNode(deadID string) {
 			continue
 		}
 
-		aliveCount := 0
-		for _, r := range cm.Replicas {
-			if cs, ok := chunkServers[r]; ok && cs.Alive {
-				aliveCount++
-			}
-		}
-
-		if aliveCount < replicationFactor {
+		if aliveReplicaCount(cm) < chunkReplication(cm) {
 			toRepair = append(toRepair, chunkID)
 		}
 	}
 	mu.Unlock()
 
 	for _, cid := range toRepair {
-		go func(chunkID string) {
-			if err := repairChunk(chunkID, deadID); err != nil {
-				log.Printf("master: repair failed for %s: %v", chunkID, err)
-			}
-		}(cid)
+		repairs.enqueue(cid, deadID)
 	}
+	log.Printf("master: queued %d repairs for dead node %s", len(toRepair), deadID)
 }
 
-// repairChunk picks a healthy source and an available target and requests a copy.
-func repairChunk(chunkID, deadID string) error {
-	// pick source and target under lock-snapshot
+// pickRepair chooses a healthy source and a placement target for one new
+// replica of chunkID. Nodes in busy are not used for either role.
+func pickRepair(chunkID string, busy map[string]bool) (source, target string, err error) {
 	mu.Lock()
+	defer mu.Unlock()
+
 	cm, ok := chunks[chunkID]
 	if !ok {
-		mu.Unlock()
-		return fmt.Errorf("chunk not found: %s", chunkID)
+		return "", "", fmt.Errorf("chunk not found: %s", chunkID)
 	}
 
 	// build set of current replicas and list alive replicas
-	replicaSet := make(map[string]bool)
+	exclude := make(map[string]bool, len(cm.Replicas)+len(busy))
+	for id := range busy {
+		exclude[id] = true
+	}
 	var aliveReplicas []string
 	for _, r := range cm.Replicas {
-		replicaSet[r] = true
+		exclude[r] = true
 		if cs, ok := chunkServers[r]; ok && cs.Alive {
 			aliveReplicas = append(aliveReplicas, r)
 		}
 	}
+	if len(aliveReplicas) == 0 {
+		return "", "", fmt.Errorf("no alive source replicas for chunk %s", chunkID)
+	}
 
-	// find candidate targets (alive chunkservers not already hosting this chunk)
-	var candidateTargets []string
-	for id, cs := range chunkServers {
-		if !cs.Alive {
+	// source: the alive, non-busy replica with the fewest repairs already running
+	for _, r := range aliveReplicas {
+		if busy[r] {
 			continue
 		}
-		if replicaSet[id] {
-			continue
+		if source == "" || chunkServers[r].inflightRepairs < chunkServers[source].inflightRepairs {
+			source = r
 		}
-		candidateTargets = append(candidateTargets, id)
 	}
-	mu.Unlock()
-
-	if len(aliveReplicas) == 0 {
-		return fmt.Errorf("no alive source replicas for chunk %s", chunkID)
+	if source == "" {
+		return "", "", errNodesBusy
 	}
-	if len(candidateTargets) == 0 {
-		return fmt.Errorf("no available targets to host new replica for chunk %s", chunkID)
+
+	// pick the target through the placement policy, skipping current replicas
+	targets := placement.Place(1, placementCandidates(exclude), replicaRacks(aliveReplicas))
+	if len(targets) == 0 {
+		if len(busy) > 0 && len(placementCandidates(cm.replicaSet())) > 0 {
+			return "", "", errNodesBusy
+		}
+		return "", "", fmt.Errorf("no available targets to host new replica for chunk %s", chunkID)
 	}
+	return source, targets[0], nil
+}
 
-	// simple selection: first alive source, first candidate target
-	source := aliveReplicas[0]
-	target := candidateTargets[0]
+// copyReplica asks source to copy chunkID to target and, on success, records
+// target as a replica in place of deadID.
+func copyReplica(chunkID, source, target, deadID string) error {
+	adjustRepairs(1, source, target)
+	defer adjustRepairs(-1, source, target)
 
 	// ask source to copy to target
 	cp := copyRequestToSource{
@@ -114,67 +117,57 @@ func repairChunk(chunkID, deadID string) error {
 	url := fmt.Sprintf("http://%s/copy_chunk", source)
 	client := &http.Client{Timeout: 20 * time.Second}
 
-	// retry loop with small backoff (3 attempts)
-	var lastErr error
-	for attempt := 1; attempt <= 3; attempt++ {
-		resp, err := client.Post(url, "application/json", bytes.NewReader(body))
-		if err != nil {
-			lastErr = fmt.Errorf("post to source failed: %w", err)
-			time.Sleep(time.Duration(attempt) * time.Second)
-			continue
-		}
-
-		respBody, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("post to source failed: %w", err)
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			lastErr = fmt.Errorf("source returned %d: %s", resp.StatusCode, string(respBody))
-			time.Sleep(time.Duration(attempt) * time.Second)
-			continue
-		}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("source returned %d: %s", resp.StatusCode, string(respBody))
+	}
+	var cr copyResponseFromSource
+	if err := json.Unmarshal(respBody, &cr); err != nil {
+		return fmt.Errorf("invalid copy response: %w", err)
+	}
+	if cr.Status != "ok" {
+		return fmt.Errorf("copy failed: %s", cr.Message)
+	}
 
-		var cr copyResponseFromSource
-		if err := json.Unmarshal(respBody, &cr); err != nil {
-			lastErr = fmt.Errorf("invalid copy response: %w", err)
-			time.Sleep(time.Duration(attempt) * time.Second)
+	// success -> update master metadata
+	mu.Lock()
+	cm, ok := chunks[chunkID]
+	if !ok {
+		mu.Unlock()
+		return fmt.Errorf("chunk %s removed during repair", chunkID)
+	}
+	// remove deadID, ensure no dup target
+	newReplicas := make([]string, 0, len(cm.Replicas)+1)
+	seen := map[string]bool{}
+	for _, r := range cm.Replicas {
+		if r == deadID {
 			continue
 		}
-		if cr.Status != "ok" {
-			lastErr = fmt.Errorf("copy failed: %s", cr.Message)
-			time.Sleep(time.Duration(attempt) * time.Second)
-			continue
+		if !seen[r] {
+			newReplicas = append(newReplicas, r)
+			seen[r] = true
 		}
-
-		// success -> update master metadata
-		mu.Lock()
-		// remove deadID, ensure no dup target
-		newReplicas := make([]string, 0, len(cm.Replicas))
-		seen := map[string]bool{}
-		for _, r := range cm.Replicas {
-			if r == deadID {
-				continue
-			}
-			if !seen[r] {
-				newReplicas = append(newReplicas, r)
-				seen[r] = true
-			}
-		}
-		if !seen[target] {
-			newReplicas = append(newReplicas, target)
-		}
-		cm.Replicas = newReplicas
-		chunks[chunkID] = cm
-		mu.Unlock()
-
-		appendOpLog("repair", map[string]any{
-			"chunk_id":    chunkID,
-			"new_replica": target,
-			"removed":     deadID,
-		})
-
-		log.Printf("master: repaired chunk %s - added replica %s (removed %s)", chunkID, target, deadID)
-		return nil
 	}
+	if !seen[target] {
+		newReplicas = append(newReplicas, target)
+	}
+	if cs, ok := chunkServers[target]; ok {
+		cs.noteCreate(time.Now())
+	}
+	cm.Replicas = newReplicas
+	appendOpLog("repair", map[string]any{
+		"chunk_id":    chunkID,
+		"new_replica": target,
+		"removed":     deadID,
+	})
+	mu.Unlock()
 
-	return lastErr
+	log.Printf("master: repaired chunk %s - added replica %s (removed %s)", chunkID, target, deadID)
+	return nil
 }
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// errNodesBusy means a repair could run but every usable node is at its
+// concurrency limit; the task stays queued without counting as a failure.
+var errNodesBusy = errors.New("all candidate nodes are at their repair limit")
+
+const (
+	repairBackoffBase  = 2 * time.Second
+	repairBackoffMax   = time.Minute
+	repairDispatchTick = time.Second
+)
+
+// repairTask asks for one chunk to be brought back to its replica target.
+type repairTask struct {
+	ChunkID   string    `json:"chunk_id"`
+	DeadID    string    `json:"dead_id,omitempty"` // replica to drop once a new copy exists
+	Live      int       `json:"live"`              // live replicas at the last dispatch pass
+	Want      int       `json:"want"`
+	Attempts  int       `json:"attempts"`
+	NotBefore time.Time `json:"not_before"`
+	Enqueued  time.Time `json:"enqueued"`
+	LastError string    `json:"last_error,omitempty"`
+	Source    string    `json:"source,omitempty"`
+	Target    string    `json:"target,omitempty"`
+}
+
+// repairQueue runs chunk repairs with the fewest live replicas first, limits
+// how many copies a node takes part in at once, and retries with backoff.
+type repairQueue struct {
+	mu       sync.Mutex
+	pending  map[string]*repairTask // chunkID -> waiting task
+	running  map[string]*repairTask // chunkID -> task being copied
+	perNode  map[string]int         // node -> running copies as source or target
+	wake     chan struct{}
+	done     int
+	failed   int
+	lastDone time.Time
+}
+
+var repairs = newRepairQueue()
+
+func newRepairQueue() *repairQueue {
+	return &repairQueue{
+		pending: make(map[string]*repairTask),
+		running: make(map[string]*repairTask),
+		perNode: make(map[string]int),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// enqueue schedules a repair of chunkID. A chunk already queued or running is
+// not added twice. Must not be called with mu held.
+func (q *repairQueue) enqueue(chunkID, deadID string) {
+	q.mu.Lock()
+	if t, ok := q.pending[chunkID]; ok {
+		if t.DeadID == "" {
+			t.DeadID = deadID
+		}
+		q.mu.Unlock()
+		return
+	}
+	if _, ok := q.running[chunkID]; ok {
+		q.mu.Unlock()
+		return
+	}
+	q.pending[chunkID] = &repairTask{ChunkID: chunkID, DeadID: deadID, Enqueued: time.Now()}
+	q.mu.Unlock()
+	q.kick()
+}
+
+func (q *repairQueue) kick() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run dispatches queued repairs until the process exits.
+func (q *repairQueue) run() {
+	ticker := time.NewTicker(repairDispatchTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-q.wake:
+		}
+		q.dispatch()
+	}
+}
+
+// dispatch refreshes priorities and starts as many ready tasks as the global
+// and per-node limits allow.
+func (q *repairQueue) dispatch() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.pending) == 0 {
+		return
+	}
+
+	// refresh live counts; drop tasks whose chunk is gone or already healthy
+	mu.Lock()
+	for cid, t := range q.pending {
+		cm, ok := chunks[cid]
+		if !ok {
+			delete(q.pending, cid)
+			continue
+		}
+		t.Live = aliveReplicaCount(cm)
+		t.Want = chunkReplication(cm)
+		if t.Live >= t.Want {
+			delete(q.pending, cid)
+		}
+	}
+	mu.Unlock()
+
+	order := make([]*repairTask, 0, len(q.pending))
+	for _, t := range q.pending {
+		order = append(order, t)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if a.Live != b.Live {
+			return a.Live < b.Live
+		}
+		if a.Want-a.Live != b.Want-b.Live {
+			return a.Want-a.Live > b.Want-b.Live
+		}
+		return a.Enqueued.Before(b.Enqueued)
+	})
+
+	now := time.Now()
+	for _, t := range order {
+		if len(q.running) >= cfg.RepairConcurrency {
+			return
+		}
+		if now.Before(t.NotBefore) {
+			continue
+		}
+
+		busy := make(map[string]bool)
+		for id, n := range q.perNode {
+			if n >= cfg.RepairPerNode {
+				busy[id] = true
+			}
+		}
+
+		source, target, err := pickRepair(t.ChunkID, busy)
+		if errors.Is(err, errNodesBusy) {
+			continue
+		}
+		if err != nil {
+			q.retryLocked(t, err)
+			continue
+		}
+
+		t.Source, t.Target = source, target
+		delete(q.pending, t.ChunkID)
+		q.running[t.ChunkID] = t
+		q.perNode[source]++
+		q.perNode[target]++
+		go q.execute(t)
+	}
+}
+
+func (q *repairQueue) execute(t *repairTask) {
+	err := copyReplica(t.ChunkID, t.Source, t.Target, t.DeadID)
+
+	q.mu.Lock()
+	delete(q.running, t.ChunkID)
+	for _, id := range []string{t.Source, t.Target} {
+		if q.perNode[id]--; q.perNode[id] <= 0 {
+			delete(q.perNode, id)
+		}
+	}
+	if err != nil {
+		log.Printf("master: repair of %s (%s -> %s) failed: %v", t.ChunkID, t.Source, t.Target, err)
+		t.Source, t.Target = "", ""
+		q.pending[t.ChunkID] = t
+		q.retryLocked(t, err)
+	} else {
+		q.done++
+		q.lastDone = time.Now()
+		// the chunk may still be short if it needs more than one new copy;
+		// the next dispatch pass re-checks and drops it once healthy
+		q.pending[t.ChunkID] = &repairTask{ChunkID: t.ChunkID, Enqueued: t.Enqueued}
+	}
+	q.mu.Unlock()
+	q.kick()
+}
+
+// retryLocked backs t off exponentially or gives up after the configured
+// number of attempts. Callers must hold q.mu.
+func (q *repairQueue) retryLocked(t *repairTask, err error) {
+	t.Attempts++
+	t.LastError = err.Error()
+	if t.Attempts >= cfg.RepairMaxAttempts {
+		delete(q.pending, t.ChunkID)
+		q.failed++
+		log.Printf("master: giving up on repair of %s after %d attempts: %v", t.ChunkID, t.Attempts, err)
+		return
+	}
+	backoff := repairBackoffBase << (t.Attempts - 1)
+	if backoff > repairBackoffMax {
+		backoff = repairBackoffMax
+	}
+	t.NotBefore = time.Now().Add(backoff)
+}
+
+type repairQueueStatus struct {
+	Pending      int            `json:"pending"`
+	Running      int            `json:"running"`
+	Completed    int            `json:"completed"`
+	Failed       int            `json:"failed"`
+	LastDoneUnix int64          `json:"last_done_unix,omitempty"`
+	PerNode      map[string]int `json:"per_node"`
+	RunningTasks []repairTask   `json:"running_tasks"`
+	NextTasks    []repairTask   `json:"next_tasks"` // highest priority pending tasks
+}
+
+func (q *repairQueue) status(limit int) repairQueueStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	st := repairQueueStatus{
+		Pending:      len(q.pending),
+		Running:      len(q.running),
+		Completed:    q.done,
+		Failed:       q.failed,
+		PerNode:      make(map[string]int, len(q.perNode)),
+		RunningTasks: []repairTask{},
+		NextTasks:    []repairTask{},
+	}
+	if !q.lastDone.IsZero() {
+		st.LastDoneUnix = q.lastDone.Unix()
+	}
+	for id, n := range q.perNode {
+		st.PerNode[id] = n
+	}
+	for _, t := range q.running {
+		st.RunningTasks = append(st.RunningTasks, *t)
+	}
+	for _, t := range q.pending {
+		st.NextTasks = append(st.NextTasks, *t)
+	}
+	sort.Slice(st.NextTasks, func(i, j int) bool {
+		a, b := st.NextTasks[i], st.NextTasks[j]
+		if a.Live != b.Live {
+			return a.Live < b.Live
+		}
+		return a.Enqueued.Before(b.Enqueued)
+	})
+	if len(st.NextTasks) > limit {
+		st.NextTasks = st.NextTasks[:limit]
+	}
+	return st
+}
+
+// queued returns the chunks waiting in or running from the queue.
+func (q *repairQueue) queued() map[string]bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make(map[string]bool, len(q.pending)+len(q.running))
+	for cid := range q.pending {
+		out[cid] = true
+	}
+	for cid := range q.running {
+		out[cid] = true
+	}
+	return out
+}
+
+// /repair_queue : queue depth, progress and the next tasks in line
+func repairQueueHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(repairs.status(50))
+}
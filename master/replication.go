@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+type setReplicationRequest struct {
+	File        string `json:"file"`
+	Replication int    `json:"replication"`
+}
+
+type setReplicationResponse struct {
+	File        string `json:"file"`
+	Replication int    `json:"replication"`
+	Chunks      int    `json:"chunks"`
+}
+
+type deleteChunkRequest struct {
+	ChunkID string `json:"chunk_id"`
+}
+
+// fileReplication returns the replica target for fm. Callers must hold mu.
+func fileReplication(fm *FileMeta) int {
+	if fm == nil || fm.Replication <= 0 {
+		return replicationFactor
+	}
+	return fm.Replication
+}
+
+// chunkReplication returns the replica target for the file cm belongs to. Callers must hold mu.
+func chunkReplication(cm *ChunkMeta) int {
+	return fileReplication(files[cm.FileName])
+}
+
+// /set_replication : change a file's replica target and converge its chunks to it
+func setReplicationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	var req setReplicationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	resp, err := setReplication(req.File, req.Replication)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// setReplication sets file's replica target and starts converging its
+// chunks to it in the background.
+func setReplication(file string, replication int) (*setReplicationResponse, error) {
+	file = cleanPath(file)
+	if file == "" || replication < 1 {
+		return nil, errorf(http.StatusBadRequest, "file and replication >= 1 required")
+	}
+
+	mu.Lock()
+	fm, ok := files[file]
+	if !ok {
+		mu.Unlock()
+		return nil, errorf(http.StatusNotFound, "file not found")
+	}
+	fm.Replication = replication
+	chunkIDs := append([]string(nil), fm.Chunks...)
+	appendOpLog("set_replication", map[string]any{
+		"file":        file,
+		"replication": replication,
+	})
+	mu.Unlock()
+
+	log.Printf("master: replication for %s set to %d (%d chunks)", file, replication, len(chunkIDs))
+
+	go func() {
+		for _, cid := range chunkIDs {
+			ensureReplication(cid)
+		}
+	}()
+
+	return &setReplicationResponse{File: file, Replication: replication, Chunks: len(chunkIDs)}, nil
+}
+
+// ensureReplication converges chunkID to its file's replica target: missing
+// replicas go through the repair queue, surplus ones are dropped one by one.
+func ensureReplication(chunkID string) {
+	for {
+		mu.Lock()
+		cm, ok := chunks[chunkID]
+		if !ok {
+			mu.Unlock()
+			return
+		}
+		want := chunkReplication(cm)
+		alive := aliveReplicaCount(cm)
+		mu.Unlock()
+
+		switch {
+		case alive < want:
+			repairs.enqueue(chunkID, "")
+			return
+		case alive > want:
+			if err := dropReplica(chunkID); err != nil {
+				log.Printf("master: replication change for %s stopped: %v", chunkID, err)
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// dropReplica removes one surplus replica of chunkID. It prefers replicas on
+// dead nodes, then draining ones, then ones sharing a rack with another
+// replica, then the node with the least free space. It never drops the
+// current lease holder or a replica on a node down for maintenance.
+func dropReplica(chunkID string) error {
+	mu.Lock()
+	cm, ok := chunks[chunkID]
+	if !ok {
+		mu.Unlock()
+		return fmt.Errorf("chunk not found: %s", chunkID)
+	}
+
+	// a node down for maintenance is coming back with its replica, so it
+	// counts as live here just as it does toward the replica target
+	now := time.Now()
+	live := func(cs *ChunkServerInfo) bool { return cs.Alive || cs.inMaintenance(now) }
+	rackCount := make(map[string]int)
+	for _, r := range cm.Replicas {
+		if cs, ok := chunkServers[r]; ok && live(cs) {
+			rackCount[cs.Rack]++
+		}
+	}
+
+	type choice struct {
+		id         string
+		dead       bool
+		draining   bool
+		sharedRack bool
+		free       float64
+	}
+	var choices []choice
+	for _, r := range cm.Replicas {
+		if r == cm.Primary && cm.LeaseValid() {
+			continue
+		}
+		c := choice{id: r, dead: true, free: 1}
+		if cs, ok := chunkServers[r]; ok {
+			if !cs.Alive && cs.inMaintenance(now) {
+				continue // can't be told to delete it until it's back
+			}
+			c.dead = !cs.Alive
+			c.draining = cs.Draining
+			c.sharedRack = rackCount[cs.Rack] > 1
+			if cs.TotalBytes > 0 {
+				c.free = float64(cs.FreeBytes) / float64(cs.TotalBytes)
+			}
+		}
+		choices = append(choices, c)
+	}
+	mu.Unlock()
+
+	if len(choices) == 0 {
+		return fmt.Errorf("no replica of %s can be dropped", chunkID)
+	}
+	sort.SliceStable(choices, func(i, j int) bool {
+		a, b := choices[i], choices[j]
+		if a.dead != b.dead {
+			return a.dead
+		}
+		if a.draining != b.draining {
+			return a.draining
+		}
+		if a.sharedRack != b.sharedRack {
+			return a.sharedRack
+		}
+		if a.free != b.free {
+			return a.free < b.free
+		}
+		return a.id < b.id
+	})
+	return removeReplicaFrom(chunkID, choices[0].id, choices[0].dead)
+}
+
+// deleteChunkOn tells node to delete its copy of chunkID.
+func deleteChunkOn(node, chunkID string) error {
+	body, _ := json.Marshal(deleteChunkRequest{ChunkID: chunkID})
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(fmt.Sprintf("http://%s/delete_chunk", node), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("delete on %s failed: %w", node, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("delete on %s returned %s", node, resp.Status)
+	}
+	return nil
+}
+
+// removeReplicaFrom deletes chunkID from node and drops node from the chunk's
+// replica list. A dead node is only dropped from the metadata.
+func removeReplicaFrom(chunkID, node string, dead bool) error {
+	// dead nodes can't be told; if one comes back with the chunk, reconciliation
+	// re-adds it and trims the surplus again
+	if !dead {
+		if err := deleteChunkOn(node, chunkID); err != nil {
+			return err
+		}
+	}
+
+	mu.Lock()
+	cm, ok := chunks[chunkID]
+	if !ok {
+		mu.Unlock()
+		return fmt.Errorf("chunk not found: %s", chunkID)
+	}
+	cm.Replicas = removeReplica(cm.Replicas, node)
+	if cm.Primary == node {
+		cm.Primary = ""
+		cm.LeaseExpires = 0
+	}
+	appendOpLog("drop_replica", map[string]any{
+		"chunk_id": chunkID,
+		"replica":  node,
+	})
+	mu.Unlock()
+	log.Printf("master: dropped replica %s of chunk %s", node, chunkID)
+	return nil
+}
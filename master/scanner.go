@@ -0,0 +1,58 @@
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// replicationScanner periodically compares every chunk's live replicas with
+// its file's target. Short chunks go to the repair queue and surplus replicas
+// are trimmed, so repairs don't depend on catching a node's death. The first
+// scan waits a full interval so chunkservers have time to re-register after a
+// master restart.
+func replicationScanner() {
+	startup := time.Now()
+	for {
+		time.Sleep(scanInterval)
+		if time.Since(startup) < heartbeatTimeout {
+			continue
+		}
+		scanReplication()
+	}
+}
+
+func scanReplication() {
+	var under, over []string
+	lost := 0
+
+	mu.Lock()
+	for cid, cm := range chunks {
+		alive := aliveReplicaCount(cm)
+		want := chunkReplication(cm)
+		switch {
+		case readableReplicaCount(cm) == 0:
+			// nothing to copy from; reconciliation re-adds the chunk if a holder returns
+			lost++
+		case alive < want:
+			under = append(under, cid)
+		case alive > want:
+			over = append(over, cid)
+		}
+	}
+	mu.Unlock()
+
+	for _, cid := range under {
+		repairs.enqueue(cid, "")
+	}
+	for _, cid := range over {
+		if rebalance.isMoving(cid) {
+			continue
+		}
+		ensureReplication(cid)
+	}
+
+	if len(under) > 0 || len(over) > 0 || lost > 0 {
+		log.Printf("master: replication scan: %d under-replicated, %d over-replicated, %d without live replicas",
+			len(under), len(over), lost)
+	}
+}
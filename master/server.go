@@ -25,13 +25,34 @@ func setupServer() *http.Server {
 	mux.HandleFunc("/list", listHandler)
 	mux.HandleFunc("/allocate", allocateHandler)
 	mux.HandleFunc("/chunk_locations", ChunkLocationsHandler)
+	mux.HandleFunc("/file_chunks", fileChunksHandler)
+	mux.HandleFunc("/lookup", lookupHandler)
+	mux.HandleFunc("/stat", statHandler)
+	mux.HandleFunc("/ls", lsHandler)
+	mux.HandleFunc("/mkdir", mkdirHandler)
+	mux.HandleFunc("/delete", deleteHandler)
+	mux.HandleFunc("/rename", renameHandler)
 	mux.HandleFunc("/get_primary", getPrimaryHandler)
 	mux.HandleFunc("/assign_primary", assignPrimaryHandler)
 	mux.HandleFunc("/renew_lease", renewLeaseHandler)
 	mux.HandleFunc("/cluster_info", clusterInfoHandler)
+	mux.HandleFunc("/report_lost", reportLostHandler)
+	mux.HandleFunc("/report_write", reportWriteHandler)
+	mux.HandleFunc("/set_replication", setReplicationHandler)
+	mux.HandleFunc("/repair_queue", repairQueueHandler)
+	mux.HandleFunc("/rebalance/start", rebalanceStartHandler)
+	mux.HandleFunc("/rebalance/stop", rebalanceStopHandler)
+	mux.HandleFunc("/rebalance/status", rebalanceStatusHandler)
+	mux.HandleFunc("/decommission", decommissionHandler)
+	mux.HandleFunc("/maintenance", maintenanceHandler)
+	mux.HandleFunc("/status", statusHandler)
+	mux.HandleFunc("/under_replicated", underReplicatedHandler)
+	mux.HandleFunc("/leases", leasesHandler)
+	mux.HandleFunc("/checkpoint", checkpointHandler)
+	mux.HandleFunc("/fsck", fsckHandler)
 
 	return &http.Server{
-		Addr:    ":8080",
+		Addr:    cfg.ListenAddr,
 		Handler: withCORS(mux),
 	}
 }
@@ -6,41 +6,146 @@ import (
 )
 
 type RegisterRequest struct {
-	Port string `json:"port"`
+	Port        string `json:"port"`
+	Addr        string `json:"addr,omitempty"`        // advertised host:port
+	UUID        string `json:"uuid,omitempty"`        // persistent, stored in the chunkserver data dir
+	Incarnation string `json:"incarnation,omitempty"` // random per chunkserver process
+	Rack        string `json:"rack,omitempty"`        // failure domain (rack or zone)
+	GRPCAddr    string `json:"grpc_addr,omitempty"`   // advertised gRPC host:port
 }
 
 type HeartbeatRequest struct {
-	Port string `json:"port"`
+	Port        string `json:"port"`
+	Addr        string `json:"addr,omitempty"`        // advertised host:port
+	UUID        string `json:"uuid,omitempty"`        // persistent, stored in the chunkserver data dir
+	Incarnation string `json:"incarnation,omitempty"` // random per chunkserver process
+	Rack        string `json:"rack,omitempty"`        // failure domain (rack or zone)
+	GRPCAddr    string `json:"grpc_addr,omitempty"`   // advertised gRPC host:port
+	NodeStats
+	Versions map[string]uint64 `json:"versions,omitempty"` // chunks committed since the last heartbeat
+}
+
+// NodeStats is the capacity and load a chunkserver reports with each heartbeat.
+type NodeStats struct {
+	TotalBytes     uint64 `json:"total_bytes"`
+	FreeBytes      uint64 `json:"free_bytes"`
+	UsedBytes      uint64 `json:"used_bytes"`
+	ChunkCount     int    `json:"chunk_count"`
+	InflightReads  int64  `json:"inflight_reads"`
+	InflightWrites int64  `json:"inflight_writes"`
 }
 
 type ChunkLocationsRequest struct {
 	ChunkID string `json:"chunk_id"`
+	LocalityHint
 }
 
 type AllocateRequest struct {
-	File      string `json:"file"`
-	SizeBytes int64  `json:"size_bytes"`
+	File        string `json:"file"`
+	SizeBytes   int64  `json:"size_bytes"`
+	Replication int    `json:"replication,omitempty"` // replicas per chunk for a new file, 0 for the default
+	// ChunkSize is the size the caller splits data by. A caller that
+	// disagrees with the master would write chunks at the wrong offsets, so
+	// it is refused before anything is allocated; 0 skips the check.
+	ChunkSize int64 `json:"chunk_size,omitempty"`
 }
 
 type AllocateResponse struct {
 	ChunkIDs  []string   `json:"chunk_ids"`
 	Locations [][]string `json:"locations"`
+	ChunkSize int64      `json:"chunk_size"`
 }
 
 type ChunkLocationsResponse struct {
 	Locations []string `json:"locations"`
 }
 
+// FileChunksRequest asks for the chunks of File with indexes
+// [Start, Start+Count); Count 0 means through the last chunk. Locations come
+// back nearest the hint first.
+type FileChunksRequest struct {
+	File  string `json:"file"`
+	Start int    `json:"start"`
+	Count int    `json:"count,omitempty"`
+	LocalityHint
+}
+
+type FileChunk struct {
+	Index     int      `json:"index"`
+	ChunkID   string   `json:"chunk_id"`
+	Length    int64    `json:"length"`
+	Locations []string `json:"locations"`
+}
+
+type FileChunksResponse struct {
+	File   string      `json:"file"`
+	Total  int         `json:"total"` // chunks in the file
+	Chunks []FileChunk `json:"chunks"`
+}
+
+// ReportWriteRequest is sent by a chunk's primary once a write of Length
+// bytes is committed on every replica.
+type ReportWriteRequest struct {
+	ChunkID string `json:"chunk_id"`
+	Addr    string `json:"addr"`
+	Length  int64  `json:"length"`
+	Version uint64 `json:"version"` // the chunk's version after the write
+}
+
+// FileRequest names a file for /lookup and /stat. /lookup lists locations
+// nearest the hint first.
+type FileRequest struct {
+	File string `json:"file"`
+	LocalityHint
+}
+
+// LookupResponse is everything a reader needs to fetch File: its chunks in
+// order with their replicas. Byte off lives in chunk off/ChunkSize.
+type LookupResponse struct {
+	File      string      `json:"file"`
+	Length    int64       `json:"length"`
+	ChunkSize int64       `json:"chunk_size"`
+	Chunks    []FileChunk `json:"chunks"`
+}
+
+type StatResponse struct {
+	File        string `json:"file"`
+	Length      int64  `json:"length"`
+	ChunkSize   int64  `json:"chunk_size"`
+	Chunks      int    `json:"chunks"`
+	Replication int    `json:"replication"`
+}
+
 type ChunkServerInfo struct {
+	Addr         string `json:"addr"`
 	Port         string `json:"port"`
+	Rack         string `json:"rack,omitempty"`
+	GRPCAddr     string `json:"grpc_addr,omitempty"`
+	UUID         string `json:"uuid,omitempty"`
+	Incarnation  string `json:"incarnation,omitempty"`
+	Conflict     string `json:"conflict,omitempty"` // last address conflict seen, cleared on register
 	Alive        bool   `json:"alive"`
+	Draining     bool   `json:"draining,omitempty"` // being decommissioned, see decommission.go
+	Drained      bool   `json:"drained,omitempty"`  // draining and every chunk it holds is replicated elsewhere
 	LastSeenUnix int64  `json:"last_seen_unix"`
 	lastSeen     time.Time
+	NodeStats
+
+	// MaintenanceUntil is the unix deadline of a planned outage, 0 when none;
+	// see maintenance.go
+	MaintenanceUntil int64 `json:"maintenance_until,omitempty"`
+
+	// placement bookkeeping, see placement.go
+	recentCreates   float64
+	createsAt       time.Time
+	inflightRepairs int
 }
 
 type FileMeta struct {
-	Name   string   `json:"name"`
-	Chunks []string `json:"chunks"`
+	Name        string   `json:"name"`
+	Chunks      []string `json:"chunks"`
+	Length      int64    `json:"length"`                // committed bytes, the sum of the chunk lengths
+	Replication int      `json:"replication,omitempty"` // 0 means the configured default
 }
 
 type ChunkMeta struct {
@@ -50,7 +155,11 @@ type ChunkMeta struct {
 	Replicas     []string `json:"replicas"`
 	Primary      string   `json:"primary,omitempty"`
 	LeaseExpires int64    `json:"lease_expires_unix"`
-	Version      uint64   `json:"version,omitempty"`
+	Version      uint64   `json:"version,omitempty"`       // bumped by every committed write; copies behind it are stale
+	Length       int64    `json:"length"`                  // valid bytes, as of the last committed write
+	SingleDomain bool     `json:"single_domain,omitempty"` // all replicas share one rack, set by the sweeper
+
+	allocated time.Time // when allocateChunks created it; zero after a restart
 }
 
 var (
@@ -58,14 +167,54 @@ var (
 	chunkServers = make(map[string]*ChunkServerInfo)
 	files        = make(map[string]*FileMeta)
 	chunks       = make(map[string]*ChunkMeta)
+	dirs         = make(map[string]bool) // explicit directories, see namespace.go
 )
 
-const (
-	heartbeatTimeout  = 10 * time.Second
-	sweepInterval     = 3 * time.Second
-	replicationFactor = 2
-	ChunkSize         = 4 * 1024 * 1024
-)
+// chunkServerID returns the ID a chunkserver is tracked under: its advertised
+// address, or localhost:<port> for chunkservers that don't send one.
+func chunkServerID(port, addr string) string {
+	if addr != "" {
+		return addr
+	}
+	return "localhost:" + port
+}
+
+// replicaSet returns the chunk's replicas as a set.
+func (c *ChunkMeta) replicaSet() map[string]bool {
+	set := make(map[string]bool, len(c.Replicas))
+	for _, r := range c.Replicas {
+		set[r] = true
+	}
+	return set
+}
+
+// canHost reports whether cs has room for another n bytes. Chunkservers
+// that haven't reported capacity yet are assumed to have room.
+func (cs *ChunkServerInfo) canHost(n int64) bool {
+	if cs.TotalBytes == 0 {
+		return true
+	}
+	return cs.FreeBytes >= uint64(n)
+}
+
+// serving reports whether cs can take new replicas and leases.
+func (cs *ChunkServerInfo) serving() bool {
+	return cs.Alive && !cs.Draining && !cs.inMaintenance(time.Now())
+}
+
+// countsTowardTarget reports whether replicas on cs count toward a chunk's
+// replica target. A node in maintenance keeps counting while it is down so
+// its chunks aren't re-replicated; a draining node no longer counts.
+func (cs *ChunkServerInfo) countsTowardTarget() bool {
+	return !cs.Draining && (cs.Alive || cs.inMaintenance(time.Now()))
+}
+
+// awaitingWrite reports whether the client that allocated c may still be
+// writing it: c is leased, or was allocated less than a lease ago, which
+// leaves the client time to ask for a primary.
+func (c *ChunkMeta) awaitingWrite() bool {
+	return c.LeaseValid() || time.Since(c.allocated) < time.Duration(leaseSeconds)*time.Second
+}
 
 func (c *ChunkMeta) LeaseValid() bool {
 	if c.LeaseExpires == 0 {
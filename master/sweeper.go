@@ -31,12 +31,21 @@ func sweeper() {
 				log.Printf("master: detected DEAD chunkserver : %s (lastSeen=%s)",
 					id, cs.lastSeen.Format(time.RFC3339))
 
+				if cs.inMaintenance(now) {
+					log.Printf("master: %s is in maintenance until %s, deferring repair",
+						id, time.Unix(cs.MaintenanceUntil, 0).Format(time.RFC3339))
+					continue
+				}
+
 				// kick off repairs for chunks that referenced this node
 				go repairNode(id)
 
 			}
 		}
+		checkDrains()
+		checkMaintenance(now)
 		mu.Unlock()
+		checkRackSpread()
 		if time.Now().Unix()%10 == 0 {
 			writeCheckpoint()
 		}